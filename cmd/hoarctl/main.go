@@ -17,10 +17,17 @@ import (
 
 	"encoding/base64"
 
+	"bytes"
+	"strings"
+
 	"github.com/jawher/mow.cli"
 	"github.com/monax/hoard/cmd"
 	"github.com/monax/hoard/config"
 	"github.com/monax/hoard/core"
+	"github.com/monax/hoard/core/encryption"
+	"github.com/monax/hoard/core/export"
+	"github.com/monax/hoard/core/reference"
+	"github.com/monax/hoard/core/storage"
 	"github.com/monax/hoard/server"
 	"google.golang.org/grpc"
 )
@@ -65,6 +72,18 @@ func main() {
 		"Put some data read from STDIN into encrypted data store and return a reference",
 		func(cmd *cli.Cmd) {
 			saltString := saltOpt(cmd)
+			suite := suiteOpt(cmd)
+			padding := paddingOpt(cmd)
+			contextString := contextOpt(cmd)
+			header := headerOpt(cmd)
+			namespace := namespaceOpt(cmd)
+			random := cmd.BoolOpt("random", false, "Encrypt with a random "+
+				"secret key rather than one derived from the data so that "+
+				"identical data is not deduplicated and cannot be confirmed to "+
+				"be stored by anyone without the reference")
+
+			cmd.Spec += " [--random]"
+			uri, mnemonic := referenceFormatOpts(cmd)
 
 			cmd.Action = func() {
 				data, err := ioutil.ReadAll(os.Stdin)
@@ -73,41 +92,68 @@ func main() {
 				}
 				ref, err := cleartextClient.Put(context.Background(),
 					&core.Plaintext{
-						Data: data,
-						Salt: parseSalt(*saltString),
+						Data:      data,
+						Salt:      parseBase64OrString(*saltString),
+						Suite:     *suite,
+						Header:    *header,
+						Namespace: *namespace,
+						Padding:   *padding,
+						Context:   parseBase64OrString(*contextString),
+						Random:    *random,
 					})
 				if err != nil {
 					fatalf("Error storing data: %v", err)
 				}
-				fmt.Printf("%s\n", jsonString(ref))
+				fmt.Printf("%s\n", referenceString(ref, *uri, *mnemonic))
 			}
 		})
 
 	hoarctlApp.Command("get",
 		"Get some data from encrypted data store and write it to STDOUT. "+
-			"Must have the JSON reference to the object passed in on STDIN (as "+
-			"generated by ref or put) or the ADDRESS and SECRET_KEY provided.",
+			"Must have the JSON or hoard: URI reference to the object passed in "+
+			"on STDIN (as generated by ref or put), a mnemonic on STDIN with "+
+			"--mnemonic, a hoard: URI passed in place of ADDRESS, or the ADDRESS "+
+			"and SECRET_KEY provided.",
 		func(cmd *cli.Cmd) {
 			address := cmd.StringArg("ADDRESS", "",
 				"The address of the data to retrieve as base64-encoded string")
 			secretKey := cmd.StringOpt("k key", "",
 				"The secret key to decrypt the data with as base64-encoded string")
 			saltString := saltOpt(cmd)
+			suite := suiteOpt(cmd)
+			padding := paddingOpt(cmd)
+			contextString := contextOpt(cmd)
+			mnemonic := cmd.BoolOpt("mnemonic", false, "Read the reference "+
+				"from STDIN as a list of words (as generated by ref --mnemonic)")
 
-			cmd.Spec = fmt.Sprintf("[--key=<SECRET_KEY>%s ADDRESS]", cmd.Spec)
+			cmd.Spec = fmt.Sprintf("--mnemonic | [[--key=<SECRET_KEY>]%s ADDRESS]",
+				cmd.Spec)
 
 			cmd.Action = func() {
 				var ref *core.Reference
 				var err error
 				// If given address then try to read reference from arguments and option
-				if address != nil && *address != "" {
+				if *mnemonic {
+					ref, err = parseMnemonic(os.Stdin)
+					if err != nil {
+						fatalf("Could not read reference mnemonic from STDIN: %v", err)
+					}
+				} else if address != nil && reference.IsURI(*address) {
+					ref, err = parseReference(strings.NewReader(*address))
+					if err != nil {
+						fatalf("Could not read reference URI: %v", err)
+					}
+				} else if address != nil && *address != "" {
 					if secretKey == nil || *secretKey == "" {
 						fatalf("A secret key must be provided in order to decrypt.")
 					}
 					ref = &core.Reference{
 						Address:   readBase64(*address),
 						SecretKey: readBase64(*secretKey),
-						Salt:      parseSalt(*saltString),
+						Salt:      parseBase64OrString(*saltString),
+						Suite:     *suite,
+						Padding:   *padding,
+						Context:   parseBase64OrString(*contextString),
 					}
 				} else {
 					// if no address then read reference from JSON on STDIN
@@ -129,6 +175,12 @@ func main() {
 		func(cmd *cli.Cmd) {
 
 			saltString := saltOpt(cmd)
+			suite := suiteOpt(cmd)
+			padding := paddingOpt(cmd)
+			contextString := contextOpt(cmd)
+			header := headerOpt(cmd)
+			namespace := namespaceOpt(cmd)
+			uri, mnemonic := referenceFormatOpts(cmd)
 
 			cmd.Action = func() {
 				data, err := ioutil.ReadAll(os.Stdin)
@@ -137,13 +189,19 @@ func main() {
 				}
 				refAndCiphertext, err := encryptionClient.Encrypt(context.Background(),
 					&core.Plaintext{
-						Data: data,
-						Salt: parseSalt(*saltString),
+						Data:      data,
+						Salt:      parseBase64OrString(*saltString),
+						Suite:     *suite,
+						Header:    *header,
+						Namespace: *namespace,
+						Padding:   *padding,
+						Context:   parseBase64OrString(*contextString),
 					})
 				if err != nil {
 					fatalf("Error generating reference: %v", err)
 				}
-				fmt.Printf("%s\n", jsonString(refAndCiphertext.Reference))
+				fmt.Printf("%s\n", referenceString(refAndCiphertext.Reference, *uri,
+					*mnemonic))
 			}
 		})
 
@@ -152,6 +210,11 @@ func main() {
 		func(cmd *cli.Cmd) {
 
 			saltString := saltOpt(cmd)
+			suite := suiteOpt(cmd)
+			padding := paddingOpt(cmd)
+			contextString := contextOpt(cmd)
+			header := headerOpt(cmd)
+			namespace := namespaceOpt(cmd)
 
 			cmd.Action = func() {
 				data, err := ioutil.ReadAll(os.Stdin)
@@ -160,8 +223,13 @@ func main() {
 				}
 				refAndCiphertext, err := encryptionClient.Encrypt(context.Background(),
 					&core.Plaintext{
-						Data: data,
-						Salt: parseSalt(*saltString),
+						Data:      data,
+						Salt:      parseBase64OrString(*saltString),
+						Suite:     *suite,
+						Header:    *header,
+						Namespace: *namespace,
+						Padding:   *padding,
+						Context:   parseBase64OrString(*contextString),
 					})
 				if err != nil {
 					fatalf("Error encrypting: %v", err)
@@ -180,6 +248,9 @@ func main() {
 			cmd.Spec = "--key=<secret key>"
 
 			saltString := saltOpt(cmd)
+			suite := suiteOpt(cmd)
+			padding := paddingOpt(cmd)
+			contextString := contextOpt(cmd)
 
 			cmd.Action = func() {
 				encryptedData, err := ioutil.ReadAll(os.Stdin)
@@ -190,7 +261,10 @@ func main() {
 					&core.ReferenceAndCiphertext{
 						Reference: &core.Reference{
 							SecretKey: readBase64(*secretKey),
-							Salt:      parseSalt(*saltString),
+							Salt:      parseBase64OrString(*saltString),
+							Suite:     *suite,
+							Padding:   *padding,
+							Context:   parseBase64OrString(*contextString),
 						},
 						Ciphertext: &core.Ciphertext{
 							EncryptedData: encryptedData,
@@ -235,6 +309,143 @@ func main() {
 			}
 		})
 
+	hoarctlApp.Command("inspect",
+		"Decode the header of an encrypted blob (if it has one) without the "+
+			"secret key. The blob is fetched from the address passed as a "+
+			"single argument as a base64 encoded string or if none is given "+
+			"encrypted data is read from STDIN (as output by encrypt)",
+		func(cmd *cli.Cmd) {
+			address := cmd.StringArg("ADDRESS", "",
+				"The address of the blob to inspect as base64-encoded string")
+
+			cmd.Spec = "[ADDRESS]"
+
+			cmd.Action = func() {
+				var encryptedData []byte
+				var err error
+				if address != nil && *address != "" {
+					ciphertext, err := storageClient.Pull(context.Background(),
+						&core.Address{Address: readBase64(*address)})
+					if err != nil {
+						fatalf("Error retrieving encrypted data: %v", err)
+					}
+					encryptedData = ciphertext.EncryptedData
+				} else {
+					encryptedData, err = ioutil.ReadAll(os.Stdin)
+					if err != nil {
+						fatalf("Could read encrypted data from STDIN: %v", err)
+					}
+				}
+				inspection := struct {
+					Size uint64
+					// Omitted if the blob has no header
+					Header *encryption.Header `json:",omitempty"`
+				}{
+					Size: uint64(len(encryptedData)),
+				}
+				inspection.Header, err = encryption.ParseHeader(encryptedData)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "No header: %v\n", err)
+				}
+				fmt.Printf("%s\n", jsonString(inspection))
+			}
+		})
+
+	hoarctlApp.Command("presign",
+		"Get a time-limited URL from which the encrypted blob stored at an "+
+			"address can be downloaded directly from the storage backend. The "+
+			"address is taken from a reference passed in on STDIN or passed in as "+
+			"a single argument as a base64 encoded string",
+		func(cmd *cli.Cmd) {
+			var addressBytes []byte
+
+			address := cmd.StringArg("ADDRESS", "",
+				"The address of the data to presign as base64-encoded string")
+			expiry := cmd.StringOpt("e expiry", "", "How long the URL should "+
+				"remain valid for as a duration such as '90s' or '2h', if omitted "+
+				"the server default is used")
+
+			cmd.Spec = "[--expiry=<duration>] [ADDRESS]"
+
+			cmd.Action = func() {
+				// If given address use it
+				if address != nil && *address != "" {
+					addressBytes = readBase64(*address)
+				} else {
+					ref, err := parseReference(os.Stdin)
+					if err != nil {
+						fatalf("Could read reference from STDIN to presign: %v", err)
+					}
+					addressBytes = ref.Address
+				}
+				var expirySeconds uint64
+				if *expiry != "" {
+					duration, err := time.ParseDuration(*expiry)
+					if err != nil || duration < time.Second {
+						fatalf("Could not parse expiry '%s' as a duration of at "+
+							"least a second", *expiry)
+					}
+					expirySeconds = uint64(duration / time.Second)
+				}
+				presignedURL, err := storageClient.Presign(context.Background(),
+					&core.PresignRequest{
+						Address:       addressBytes,
+						ExpirySeconds: expirySeconds,
+					})
+				if err != nil {
+					fatalf("Error presigning: %v", err)
+				}
+				fmt.Printf("%s\n", jsonString(presignedURL))
+			}
+		})
+
+	hoarctlApp.Command("verifycap",
+		"Derive the verify capability for a blob from its reference passed in "+
+			"on STDIN. The verify capability can be given to verify to check the "+
+			"blob hashes to its address and carries the key commitment without "+
+			"being able to decrypt it. Only blobs encrypted with a key-committing "+
+			"suite have verify capabilities.",
+		func(cmd *cli.Cmd) {
+			cmd.Action = func() {
+				ref, err := parseReference(os.Stdin)
+				if err != nil {
+					fatalf("Could read reference from STDIN: %v", err)
+				}
+				verifyCap, err := hoardReference(ref).VerifyCap()
+				if err != nil {
+					fatalf("Error deriving verify capability: %v", err)
+				}
+				fmt.Printf("%s\n", jsonString(&core.VerifyCap{
+					Address:       verifyCap.Address,
+					KeyCommitment: verifyCap.KeyCommitment,
+				}))
+			}
+		})
+
+	hoarctlApp.Command("verify",
+		"Check the encrypted blob referred to by a verify capability passed in "+
+			"on STDIN (as generated by verifycap) hashes to its address and begins "+
+			"with the key commitment in the verify capability",
+		func(cmd *cli.Cmd) {
+			cmd.Action = func() {
+				bs, err := ioutil.ReadAll(os.Stdin)
+				if err != nil {
+					fatalf("Could read verify capability from STDIN: %v", err)
+				}
+				verifyCap := new(core.VerifyCap)
+				err = json.Unmarshal(bs, verifyCap)
+				if err != nil {
+					fatalf("Could not parse verify capability: %v", err)
+				}
+				verification, err := storageClient.Verify(context.Background(),
+					verifyCap)
+				if err != nil {
+					fatalf("Verification failed: %v", err)
+				}
+				fmt.Printf("%s\n", jsonString(verification))
+			}
+		})
+
 	hoarctlApp.Command("insert",
 		"Insert encrypted (presumably) data on STDIN directly into store at "+
 			"its address which is written to STDOUT.",
@@ -291,6 +502,88 @@ func main() {
 			}
 		})
 
+	hoarctlApp.Command("export",
+		"Write a tar archive of the encrypted blobs of references passed in "+
+			"on STDIN as a stream of JSON (as generated by ref or put) or of "+
+			"ADDRESS arguments as base64 encoded strings, for transfer to "+
+			"another Hoard with import. Secret keys are not written to the "+
+			"archive unless requested.",
+		func(cmd *cli.Cmd) {
+			addresses := cmd.StringsArg("ADDRESS", nil,
+				"The addresses of the blobs to archive as base64-encoded strings")
+			output := cmd.StringOpt("o output", "",
+				"File to write the archive to (defaults to STDOUT)")
+			includeSecretKeys := cmd.BoolOpt("include-secret-keys", false,
+				"Write the references passed on STDIN including their secret "+
+					"keys into the archive index so anyone with the archive "+
+					"can decrypt the blobs")
+
+			cmd.Spec = "[--output=<archive file>] [--include-secret-keys] [ADDRESS...]"
+
+			cmd.Action = func() {
+				var refs []*reference.Ref
+				if len(*addresses) > 0 {
+					for _, address := range *addresses {
+						refs = append(refs, reference.New(readBase64(address), nil, nil))
+					}
+				} else {
+					pbRefs, err := parseReferences(os.Stdin)
+					if err != nil {
+						fatalf("Could not read references from STDIN to "+
+							"export: %v", err)
+					}
+					for _, ref := range pbRefs {
+						refs = append(refs, hoardReference(ref))
+					}
+				}
+				w := io.Writer(os.Stdout)
+				if *output != "" {
+					file, err := os.Create(*output)
+					if err != nil {
+						fatalf("Could not create archive file: %v", err)
+					}
+					defer file.Close()
+					w = file
+				}
+				_, err := export.ExportArchive(w, &storageClientStore{storageClient},
+					refs, *includeSecretKeys)
+				if err != nil {
+					fatalf("Error exporting archive: %v", err)
+				}
+			}
+		})
+
+	hoarctlApp.Command("import",
+		"Verify each encrypted blob in an archive written by export and push "+
+			"it into the store. Any references recorded in the archive are "+
+			"written to STDOUT as JSON.",
+		func(cmd *cli.Cmd) {
+			input := cmd.StringArg("ARCHIVE", "",
+				"The archive file to import (defaults to STDIN)")
+
+			cmd.Spec = "[ARCHIVE]"
+
+			cmd.Action = func() {
+				r := io.Reader(os.Stdin)
+				if *input != "" {
+					file, err := os.Open(*input)
+					if err != nil {
+						fatalf("Could not open archive file: %v", err)
+					}
+					defer file.Close()
+					r = file
+				}
+				index, err := export.ImportArchive(r, &storageClientStore{storageClient})
+				if err != nil {
+					fatalf("Error importing archive: %v", err)
+				}
+				for _, ref := range index.Refs {
+					fmt.Printf("%s\n", jsonString(protobufReference(ref)))
+				}
+				fmt.Fprintf(os.Stderr, "Imported %v blobs\n", len(index.Blobs))
+			}
+		})
+
 	hoarctlApp.Run(os.Args)
 }
 
@@ -305,15 +598,74 @@ func saltOpt(cmd *cli.Cmd) *string {
 	return saltString
 }
 
-func parseSalt(saltString string) []byte {
-	if saltString == "" {
+func contextOpt(cmd *cli.Cmd) *string {
+	contextString := cmd.StringOpt("context", "", "Context (such as a "+
+		"tenant id or contract address) to authenticate with the data so that "+
+		"it only decrypts when the same context is given. Will be parsed as "+
+		"base64 encoded string if this is possible, otherwise will be "+
+		"interpreted as the bytes of the string itself.")
+
+	cmd.Spec += " [--context=<base64-encoded or string context>]"
+	return contextString
+}
+
+func suiteOpt(cmd *cli.Cmd) *string {
+	suite := cmd.StringOpt("c suite", "", fmt.Sprintf("The cipher suite to "+
+		"use for encryption and decryption, one of: %s. If omitted %s is used.",
+		strings.Join(encryption.SuiteNames(), ", "), encryption.DefaultSuite))
+
+	cmd.Spec += " [--suite=<cipher suite>]"
+	return suite
+}
+
+func paddingOpt(cmd *cli.Cmd) *string {
+	padding := cmd.StringOpt("p padding", "", fmt.Sprintf("The padding "+
+		"scheme applied to the data before encryption to hide its exact size, "+
+		"one of: %s. If omitted no padding is used.",
+		strings.Join(encryption.PaddingNames(), ", ")))
+
+	cmd.Spec += " [--padding=<padding scheme>]"
+	return padding
+}
+
+func headerOpt(cmd *cli.Cmd) *bool {
+	header := cmd.BoolOpt("header", false, "Prefix the encrypted data with a "+
+		"header recording the format version and cipher suite that can be "+
+		"read with inspect. Changes the address of the encrypted data.")
+
+	cmd.Spec += " [--header]"
+	return header
+}
+
+func namespaceOpt(cmd *cli.Cmd) *string {
+	namespace := cmd.StringOpt("n namespace", "", "The namespace whose "+
+		"convergence secret configured on the hoard daemon should be mixed "+
+		"into the secret key. If omitted the default secret is used if one "+
+		"is configured.")
+
+	cmd.Spec += " [--namespace=<namespace>]"
+	return namespace
+}
+
+func referenceFormatOpts(cmd *cli.Cmd) (uri *bool, mnemonic *bool) {
+	uri = cmd.BoolOpt("uri", false, "Output the reference as a compact "+
+		"hoard: URI rather than JSON")
+	mnemonic = cmd.BoolOpt("mnemonic", false, "Output the reference as a "+
+		"list of words with a checksum for writing down as a paper backup "+
+		"(restore it with 'get --mnemonic')")
+	cmd.Spec += " [--uri | --mnemonic]"
+	return
+}
+
+func parseBase64OrString(str string) []byte {
+	if str == "" {
 		return nil
 	}
-	saltBytes, err := base64.StdEncoding.DecodeString(saltString)
+	bs, err := base64.StdEncoding.DecodeString(str)
 	if err == nil {
-		return saltBytes
+		return bs
 	}
-	return ([]byte)(saltString)
+	return ([]byte)(str)
 }
 
 func jsonString(v interface{}) string {
@@ -325,12 +677,31 @@ func jsonString(v interface{}) string {
 
 }
 
+func referenceString(ref *core.Reference, uri, mnemonic bool) string {
+	if uri {
+		return hoardReference(ref).URI()
+	}
+	if mnemonic {
+		return hoardReference(ref).Mnemonic()
+	}
+	return jsonString(ref)
+}
+
+// Read a reference as JSON or a hoard: URI
 func parseReference(r io.Reader) (*core.Reference, error) {
-	ref := new(core.Reference)
 	bs, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
+	str := strings.TrimSpace(string(bs))
+	if reference.IsURI(str) {
+		ref, err := reference.FromURI(str)
+		if err != nil {
+			return nil, err
+		}
+		return protobufReference(ref), nil
+	}
+	ref := new(core.Reference)
 	err = json.Unmarshal(bs, ref)
 	if err != nil {
 		return nil, err
@@ -338,6 +709,71 @@ func parseReference(r io.Reader) (*core.Reference, error) {
 	return ref, nil
 }
 
+func parseMnemonic(r io.Reader) (*core.Reference, error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := reference.FromMnemonic(string(bs))
+	if err != nil {
+		return nil, err
+	}
+	return protobufReference(ref), nil
+}
+
+// Read a stream of references as JSON or as whitespace-separated hoard: URIs
+func parseReferences(r io.Reader) ([]*core.Reference, error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var refs []*core.Reference
+	if reference.IsURI(strings.TrimSpace(string(bs))) {
+		for _, uri := range strings.Fields(string(bs)) {
+			ref, err := reference.FromURI(uri)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, protobufReference(ref))
+		}
+		return refs, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(bs))
+	for {
+		ref := new(core.Reference)
+		err := decoder.Decode(ref)
+		if err == io.EOF {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+}
+
+func hoardReference(ref *core.Reference) *reference.Ref {
+	return &reference.Ref{
+		Address:   ref.Address,
+		SecretKey: ref.SecretKey,
+		Salt:      ref.Salt,
+		Suite:     ref.Suite,
+		Padding:   ref.Padding,
+		Context:   ref.Context,
+	}
+}
+
+func protobufReference(ref *reference.Ref) *core.Reference {
+	return &core.Reference{
+		Address:   ref.Address,
+		SecretKey: ref.SecretKey,
+		Salt:      ref.Salt,
+		Suite:     ref.Suite,
+		Padding:   ref.Padding,
+		Context:   ref.Context,
+	}
+}
+
 func readBase64(base64String string) []byte {
 	secretKeyBytes, err := base64.StdEncoding.DecodeString(base64String)
 	if err != nil {
@@ -350,3 +786,40 @@ func fatalf(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
 	os.Exit(1)
 }
+
+// Adapts the storage service to the store interfaces used for archives
+type storageClientStore struct {
+	client core.StorageClient
+}
+
+func (scs *storageClientStore) Get(address []byte) ([]byte, error) {
+	ciphertext, err := scs.client.Pull(context.Background(),
+		&core.Address{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	return ciphertext.EncryptedData, nil
+}
+
+func (scs *storageClientStore) Stat(address []byte) (*storage.StatInfo, error) {
+	statInfo, err := scs.client.Stat(context.Background(),
+		&core.Address{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	return &storage.StatInfo{
+		Exists: statInfo.Exists,
+		Size:   statInfo.Size,
+	}, nil
+}
+
+// Put data at its archived address, which the server checks it matches even
+// if it was made with a different hash algorithm
+func (scs *storageClientStore) Put(address, data []byte) error {
+	_, err := scs.client.PushAt(context.Background(),
+		&core.AddressAndCiphertext{
+			Address:    address,
+			Ciphertext: &core.Ciphertext{EncryptedData: data},
+		})
+	return err
+}
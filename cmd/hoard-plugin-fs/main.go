@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jawher/mow.cli"
+	"github.com/monax/hoard/core/storage"
+	"github.com/monax/hoard/core/storage/plugin"
+)
+
+// A reference storage plugin serving a filesystem store. It is not intended to
+// be run directly but to be launched by Hoard with config like:
+//
+//	[Storage]
+//	  StorageType = "plugin"
+//	  Command = "hoard-plugin-fs"
+//	  Args = ["--root", "/var/lib/hoard"]
+func main() {
+	pluginApp := cli.App("hoard-plugin-fs",
+		"Reference Hoard storage plugin backed by the filesystem")
+
+	rootOpt := pluginApp.StringOpt("r root", "",
+		"Root directory in which to store blobs")
+	encodingOpt := pluginApp.StringOpt("e encoding", storage.Base32EncodingName,
+		"Address encoding used to name files, one of: base64, base32, or hex")
+
+	pluginApp.Spec = "--root=<root directory> [--encoding=<address encoding>]"
+
+	pluginApp.Action = func() {
+		addressEncoding, err := storage.GetAddressEncoding(*encodingOpt)
+		if err != nil {
+			fatalf("Could not get address encoding: %s", err)
+		}
+		store, err := storage.NewFileSystemStore(*rootOpt, addressEncoding)
+		if err != nil {
+			fatalf("Could not create filesystem store: %s", err)
+		}
+		err = plugin.Serve(store)
+		if err != nil {
+			fatalf("Could not serve storage plugin: %s", err)
+		}
+	}
+
+	pluginApp.Run(os.Args)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
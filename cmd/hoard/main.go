@@ -1,7 +1,10 @@
 package main
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"os/signal"
@@ -16,6 +19,10 @@ import (
 	"github.com/monax/hoard/config"
 	"github.com/monax/hoard/config/logging"
 	"github.com/monax/hoard/config/storage"
+	"github.com/monax/hoard/core/export"
+	"github.com/monax/hoard/core/fsck"
+	"github.com/monax/hoard/core/migrate"
+	corestorage "github.com/monax/hoard/core/storage"
 	"github.com/monax/hoard/server"
 )
 
@@ -55,12 +62,27 @@ func main() {
 			}
 		}
 
+		hashAlgorithm, err := corestorage.GetHashAlgorithm(conf.Storage.HashAlgorithm)
+		if err != nil {
+			fatalf("Could not get hash algorithm from storage config: %s", err)
+		}
+
+		var convergenceSecrets map[string][]byte
+		if conf.Storage.Convergence != nil {
+			convergenceSecrets, err = conf.Storage.Convergence.Secrets()
+			if err != nil {
+				fatalf("Could not get convergence secrets from storage config: %s",
+					err)
+			}
+		}
+
 		store, err := storage.StoreFromStorageConfig(conf.Storage, logger)
 		if err != nil {
 			fatalf("Could not configure store from storage config: %s", err)
 		}
 
-		serv := server.New(*listenAddressOpt, store, logger)
+		serv := server.New(*listenAddressOpt, store, hashAlgorithm,
+			convergenceSecrets, logger)
 		// Catch interrupt etc
 		signalCh := make(chan os.Signal, 1)
 		signal.Notify(signalCh, os.Interrupt, os.Kill, syscall.SIGTERM)
@@ -69,8 +91,24 @@ func main() {
 			printf("\nCaught %s signal: shutting down...", sig)
 			// Make sure we clean up
 			serv.Stop()
+			closeStore(store)
 			os.Exit(0)
 		}(signalCh)
+		// Snapshot on request from 'hoard snapshot'
+		if len(snapshotSignals) > 0 {
+			snapshotCh := make(chan os.Signal, 1)
+			signal.Notify(snapshotCh, snapshotSignals...)
+			go func() {
+				for range snapshotCh {
+					err := corestorage.Snapshot(store)
+					if err != nil {
+						printf("Could not snapshot store: %s", err)
+						continue
+					}
+					printf("Wrote snapshot of %s", store.Name())
+				}
+			}()
+		}
 
 		printf("Starting hoard daemon on %s with %s...", *listenAddressOpt,
 			store.Name())
@@ -119,6 +157,23 @@ func main() {
 					}
 				})
 
+			initCmd.Command("plugin", "Emit initial config with a storage "+
+				"plugin backend.",
+				func(pluginCmd *cli.Cmd) {
+					pluginCmd.Action = func() {
+						conf.Storage = storage.DefaultPluginConfig()
+					}
+				})
+
+			initCmd.Command("fault", "Emit initial config with a fault "+
+				"injecting storage backend for testing clients against a "+
+				"misbehaving store (not for production).",
+				func(faultCmd *cli.Cmd) {
+					faultCmd.Action = func() {
+						conf.Storage = storage.DefaultFaultConfig()
+					}
+				})
+
 			initCmd.After = func() {
 				if *outputOpt == "-" {
 					fmt.Print(conf.TOMLString())
@@ -140,9 +195,331 @@ func main() {
 			}
 		})
 
+	hoardApp.Command("export-static", "Export the ciphertexts stored at "+
+		"some addresses from the configured store into a directory tree "+
+		"along with an index manifest so that it can be served by any web "+
+		"server. Addresses are read from arguments as base64-encoded strings or "+
+		"if none are provided from JSON references (as output by hoarctl) on STDIN.",
+		func(exportCmd *cli.Cmd) {
+			outputOpt := exportCmd.StringOpt("o output", "",
+				"Directory to export into, will be created if it does not exist")
+			encodingOpt := exportCmd.StringOpt("e encoding",
+				corestorage.Base32EncodingName, "Address encoding used to name "+
+					"exported files, one of: base64, base32, or hex")
+			shardDepthOpt := exportCmd.IntOpt("d shard-depth", 0,
+				"Number of levels of subdirectories to spread blobs over")
+			shardWidthOpt := exportCmd.IntOpt("w shard-width", 2,
+				"Number of characters of the encoded address used to name "+
+					"each level of subdirectory")
+			checksumsOpt := exportCmd.BoolOpt("checksums", false,
+				"Write a sha256sum-compatible checksum file alongside each blob")
+			addressesArg := exportCmd.StringsArg("ADDRESS", nil,
+				"The addresses of the blobs to export as base64-encoded strings")
+
+			exportCmd.Spec = "--output=<output directory> [--encoding=<address " +
+				"encoding>] [--shard-depth=<levels>] [--shard-width=<characters>] " +
+				"[--checksums] [ADDRESS...]"
+
+			exportCmd.Action = func() {
+				conf, err := hoardConfig(*configFileOpt)
+				if err != nil {
+					fatalf("Could not get Hoard config: %s", err)
+				}
+				store, err := storage.StoreFromStorageConfig(conf.Storage, nil)
+				if err != nil {
+					fatalf("Could not configure store from storage config: %s", err)
+				}
+				addresses, err := readAddresses(*addressesArg, os.Stdin)
+				if err != nil {
+					fatalf("Could not read addresses to export: %s", err)
+				}
+				se, err := export.NewStaticExporter(*outputOpt, *encodingOpt,
+					export.Sharding{
+						Depth: *shardDepthOpt,
+						Width: *shardWidthOpt,
+					}, *checksumsOpt)
+				if err != nil {
+					fatalf("Could not create static exporter: %s", err)
+				}
+				report, err := se.Export(store, addresses)
+				closeStore(store)
+				if err != nil {
+					fatalf("Error exporting from %s: %s", store.Name(), err)
+				}
+				printf("Exported %v blobs and skipped %v already exported "+
+					"blobs to '%s'", len(report.Exported), len(report.Skipped),
+					*outputOpt)
+			}
+		})
+
+	hoardApp.Command("migrate", "Copy every blob from the store of one "+
+		"Hoard config to the store of another, for example to change backend "+
+		"or AddressEncoding. Blobs already present are skipped and each copy "+
+		"is verified against its address. The source store must support "+
+		"listing, so its storage config cannot have a Blinding section.",
+		func(migrateCmd *cli.Cmd) {
+			fromOpt := migrateCmd.StringOpt("from", "",
+				"Hoard config file whose storage to copy from")
+			toOpt := migrateCmd.StringOpt("to", "",
+				"Hoard config file whose storage to copy to")
+			workersOpt := migrateCmd.IntOpt("w workers", migrate.DefaultWorkers,
+				"Number of blobs to copy concurrently")
+			checkpointOpt := migrateCmd.StringOpt("checkpoint", "",
+				"File in which to record migrated addresses so that an "+
+					"interrupted migration can be resumed by running it again")
+
+			migrateCmd.Spec = "--from=<config file> --to=<config file> " +
+				"[--workers=<workers>] [--checkpoint=<checkpoint file>]"
+
+			migrateCmd.Action = func() {
+				source := storeFromConfigFile(*fromOpt)
+				destination := storeFromConfigFile(*toOpt)
+				report, err := migrate.Migrate(source, destination,
+					migrate.Options{
+						Workers:        *workersOpt,
+						CheckpointFile: *checkpointOpt,
+					})
+				closeStore(source)
+				closeStore(destination)
+				if report != nil {
+					for _, failure := range report.Failed {
+						printf("Could not migrate blob: %s", failure)
+					}
+				}
+				if err != nil {
+					fatalf("Error migrating from %s to %s: %s", source.Name(),
+						destination.Name(), err)
+				}
+				printf("Copied %v blobs, skipped %v blobs already present and "+
+					"failed to migrate %v blobs", len(report.Copied),
+					len(report.Skipped), len(report.Failed))
+				if len(report.Failed) > 0 {
+					os.Exit(1)
+				}
+			}
+		})
+
+	hoardApp.Command("fsck", "Check every blob in the configured store "+
+		"against its address and write a JSON report of missing, corrupt, "+
+		"and unparseable entries to STDOUT. Exits non-zero if any problem "+
+		"remains unresolved. Any Integrity section in the storage config is "+
+		"ignored so that problems are reported rather than handled silently. "+
+		"Stores with a Blinding section cannot be checked since their "+
+		"addresses cannot be listed.",
+		func(fsckCmd *cli.Cmd) {
+			repairFromOpt := fsckCmd.StringOpt("repair-from", "",
+				"Hoard config file whose storage to repair missing or corrupt "+
+					"blobs from")
+			deleteOpt := fsckCmd.BoolOpt("delete", false,
+				"Delete corrupt blobs that are not repaired")
+			rateOpt := fsckCmd.IntOpt("r rate", 0,
+				"Maximum number of blobs to check per second (unlimited if 0)")
+
+			fsckCmd.Spec = "[--repair-from=<config file>] [--delete] " +
+				"[--rate=<blobs per second>]"
+
+			fsckCmd.Action = func() {
+				conf, err := hoardConfig(*configFileOpt)
+				if err != nil {
+					fatalf("Could not get Hoard config: %s", err)
+				}
+				storageConfig := *conf.Storage
+				storageConfig.Integrity = nil
+				store, err := storage.StoreFromStorageConfig(&storageConfig, nil)
+				if err != nil {
+					fatalf("Could not configure store from storage config: %s", err)
+				}
+				options := fsck.Options{
+					DeleteCorrupt: *deleteOpt,
+					Rate:          float64(*rateOpt),
+				}
+				if *repairFromOpt != "" {
+					options.RepairStore = storeFromConfigFile(*repairFromOpt)
+				}
+				report, err := fsck.Check(store, options, nil)
+				closeStore(store)
+				if options.RepairStore != nil {
+					closeStore(options.RepairStore)
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				encodeErr := encoder.Encode(report)
+				if encodeErr != nil {
+					fatalf("Could not write fsck report: %s", encodeErr)
+				}
+				if err != nil {
+					fatalf("Could not check %s: %s", store.Name(), err)
+				}
+				if !report.Clean() {
+					printf("Found %v problems in %v entries of which some remain "+
+						"unresolved", len(report.Problems), report.Checked)
+					os.Exit(1)
+				}
+			}
+		})
+
+	hoardApp.Command("blind", "Migrate an existing store to blinded "+
+		"addresses by copying everything in the backend to the keyed hash of "+
+		"its address. Add a Blinding section to the storage config with a "+
+		"new secret before running this with the daemon stopped. Already "+
+		"blinded blobs are skipped so it can be re-run to resume.",
+		func(blindCmd *cli.Cmd) {
+			deleteOpt := blindCmd.BoolOpt("delete", false,
+				"Delete each blob from its unblinded address once copied")
+
+			blindCmd.Action = func() {
+				conf, err := hoardConfig(*configFileOpt)
+				if err != nil {
+					fatalf("Could not get Hoard config: %s", err)
+				}
+				if conf.Storage.Blinding == nil {
+					fatalf("Storage config must contain a Blinding section to " +
+						"migrate to blinded addresses")
+				}
+				secret, err := conf.Storage.Blinding.Secret()
+				if err != nil {
+					fatalf("Could not get blinding secret: %s", err)
+				}
+				// The backend as it was before blinding was configured
+				backendConfig := *conf.Storage
+				backendConfig.Blinding = nil
+				backendConfig.Integrity = nil
+				backend, err := storage.StoreFromStorageConfig(&backendConfig, nil)
+				if err != nil {
+					fatalf("Could not configure store from storage config: %s", err)
+				}
+				blindingStore, err := corestorage.NewBlindingStore(backend, secret)
+				if err != nil {
+					closeStore(backend)
+					fatalf("Could not create blinding store: %s", err)
+				}
+				migrated, failed := 0, 0
+				err = blindingStore.Migrate(*deleteOpt,
+					func(address []byte, err error) error {
+						if err != nil {
+							failed++
+							printf("Could not migrate %s: %s",
+								base64.StdEncoding.EncodeToString(address), err)
+							return nil
+						}
+						migrated++
+						return nil
+					})
+				closeStore(backend)
+				if err != nil {
+					fatalf("Error migrating %s: %s", backend.Name(), err)
+				}
+				printf("Migrated %v blobs to blinded addresses with %v failures",
+					migrated, failed)
+			}
+		})
+
+	hoardApp.Command("rotate-keys", "Re-encrypt every blob in the backend "+
+		"that is not encrypted under the current at-rest encryption master "+
+		"key with the current key. Old keys can be removed from the keyring "+
+		"once this completes without failures. Safe to run while the daemon is "+
+		"running provided both use the same keyring.",
+		func(rotateCmd *cli.Cmd) {
+			rotateCmd.Action = func() {
+				conf, err := hoardConfig(*configFileOpt)
+				if err != nil {
+					fatalf("Could not get Hoard config: %s", err)
+				}
+				arec := conf.Storage.AtRestEncryption
+				if arec == nil {
+					fatalf("Storage config must contain an AtRestEncryption " +
+						"section to rotate keys")
+				}
+				keyring, err := arec.Keyring()
+				if err != nil {
+					fatalf("Could not load keyring: %s", err)
+				}
+				// The backend beneath the at-rest encryption layer
+				backendConfig := *conf.Storage
+				backendConfig.AtRestEncryption = nil
+				backendConfig.Blinding = nil
+				backendConfig.Integrity = nil
+				backend, err := storage.StoreFromStorageConfig(&backendConfig, nil)
+				if err != nil {
+					fatalf("Could not configure store from storage config: %s", err)
+				}
+				rotated, failed := 0, 0
+				err = corestorage.NewEncryptedStore(backend, keyring).Rotate(
+					func(address []byte, err error) error {
+						if err != nil {
+							failed++
+							printf("Could not rotate %s: %s",
+								base64.StdEncoding.EncodeToString(address), err)
+							return nil
+						}
+						rotated++
+						return nil
+					})
+				closeStore(backend)
+				if err != nil {
+					fatalf("Error rotating keys in %s: %s", backend.Name(), err)
+				}
+				printf("Re-encrypted %v blobs with master key %v with %v "+
+					"failures", rotated, keyring.CurrentKeyID(), failed)
+			}
+		})
+
+	hoardApp.Command("snapshot", "Ask a running Hoard daemon to write a "+
+		"snapshot of its memory store to the SnapshotFile in its memory "+
+		"storage config.",
+		func(snapshotCmd *cli.Cmd) {
+			pidArg := snapshotCmd.IntArg("PID", 0,
+				"The process id of the Hoard daemon (e.g. from 'pidof hoard')")
+
+			snapshotCmd.Action = func() {
+				err := signalSnapshot(*pidArg)
+				if err != nil {
+					fatalf("Could not signal Hoard daemon with pid %v to "+
+						"snapshot: %s", *pidArg, err)
+				}
+			}
+		})
+
 	hoardApp.Run(os.Args)
 }
 
+// Some stores (such as plugins) hold resources that need releasing
+func closeStore(store corestorage.Store) {
+	err := corestorage.Close(store)
+	if err != nil {
+		fatalf("Could not close %s: %s", store.Name(), err)
+	}
+}
+
+// Read base64-encoded addresses or if there are none a stream of JSON
+// references from which to take addresses
+func readAddresses(addressStrings []string, r io.Reader) ([][]byte, error) {
+	addresses := make([][]byte, 0, len(addressStrings))
+	for _, addressString := range addressStrings {
+		address, err := base64.StdEncoding.DecodeString(addressString)
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode address '%s' as "+
+				"base64-encoded string", addressString)
+		}
+		addresses = append(addresses, address)
+	}
+	if len(addresses) > 0 {
+		return addresses, nil
+	}
+	decoder := json.NewDecoder(r)
+	for {
+		ref := new(struct{ Address []byte })
+		err := decoder.Decode(ref)
+		if err == io.EOF {
+			return addresses, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, ref.Address)
+	}
+}
+
 // Print informational output to Stderr
 func printf(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
@@ -153,6 +530,19 @@ func fatalf(format string, args ...interface{}) {
 	os.Exit(1)
 }
 
+func storeFromConfigFile(configFilePath string) corestorage.Store {
+	conf, err := hoardConfig(configFilePath)
+	if err != nil {
+		fatalf("Could not get Hoard config from '%s': %s", configFilePath, err)
+	}
+	store, err := storage.StoreFromStorageConfig(conf.Storage, nil)
+	if err != nil {
+		fatalf("Could not configure store from storage config in '%s': %s",
+			configFilePath, err)
+	}
+	return store
+}
+
 func hoardConfig(configFilePath string) (*config.HoardConfig, error) {
 	// First try to read any provided config
 	if configFilePath != "" {
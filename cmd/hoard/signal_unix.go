@@ -0,0 +1,15 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals on which the daemon snapshots its store
+var snapshotSignals = []os.Signal{syscall.SIGUSR1}
+
+func signalSnapshot(pid int) error {
+	return syscall.Kill(pid, syscall.SIGUSR1)
+}
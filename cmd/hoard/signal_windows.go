@@ -0,0 +1,17 @@
+// +build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// Windows has no user signals so snapshots can only be taken on shutdown or
+// at intervals
+var snapshotSignals []os.Signal
+
+func signalSnapshot(pid int) error {
+	return errors.New("Signalling the daemon to snapshot is not supported " +
+		"on Windows")
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// The presence of a Blinding section in a storage config causes data to be
+// stored in the backend under a keyed hash of its address (see
+// storage.NewBlindingStore). Exactly one source for the secret (which should
+// be at least 16 random bytes, base64-encoded) should be given. Losing the
+// secret means losing access to everything stored under it.
+type BlindingConfig struct {
+	// File containing the base64-encoded secret
+	SecretFile string
+	// Environment variable containing the base64-encoded secret
+	SecretEnv string
+}
+
+func NewBlindingConfig(secretFile string) *BlindingConfig {
+	return &BlindingConfig{
+		SecretFile: secretFile,
+	}
+}
+
+func (bc *BlindingConfig) Secret() ([]byte, error) {
+	return readBase64Secret("blinding secret", bc.SecretFile, bc.SecretEnv)
+}
+
+// Read a base64-encoded secret from exactly one of file or the environment
+// variable env
+func readBase64Secret(description, file, env string) ([]byte, error) {
+	encodedSecret, err := readSecret(description, file, env)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedSecret))
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode %s as base64: %s", description,
+			err)
+	}
+	return secret, nil
+}
+
+// Read a secret from exactly one of file or the environment variable env
+func readSecret(description, file, env string) (string, error) {
+	switch {
+	case file != "" && env != "":
+		return "", fmt.Errorf("Only one of a file or an environment variable "+
+			"may be given for the %s", description)
+	case file != "":
+		bs, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("Could not read %s: %s", description, err)
+		}
+		return string(bs), nil
+	case env != "":
+		secret := os.Getenv(env)
+		if secret == "" {
+			return "", fmt.Errorf("Environment variable '%s' holding %s is "+
+				"empty", env, description)
+		}
+		return secret, nil
+	default:
+		return "", errors.New("A file or an environment variable must be " +
+			"given for the " + description)
+	}
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlindingConfig(t *testing.T) {
+	storageConfig := DefaultMemoryConfig()
+	storageConfig.Blinding = &BlindingConfig{SecretEnv: "HOARD_TEST_BLINDING_SECRET"}
+	assertStorageConfigSerialisation(t, storageConfig)
+
+	_, err := StoreFromStorageConfig(storageConfig, nil)
+	assert.Error(t, err)
+
+	os.Setenv("HOARD_TEST_BLINDING_SECRET", "c2VjcmV0c2VjcmV0c2VjcmV0c2VjcmV0")
+	defer os.Unsetenv("HOARD_TEST_BLINDING_SECRET")
+	store, err := StoreFromStorageConfig(storageConfig, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "blindingStore<memoryStore>", store.Name())
+
+	storageConfig.Blinding.SecretFile = "/some/file"
+	_, err = StoreFromStorageConfig(storageConfig, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package storage
+
+import "fmt"
+
+const MinConvergenceSecretLength = 16
+
+// The presence of a Convergence section in a storage config causes a secret to
+// be mixed into the derivation of the secret key of each blob (see
+// encryption.Options) so that only those holding the secret can confirm that
+// a guessed plaintext is stored and blobs are only deduplicated with others
+// encrypted under the same secret. Secrets (which must be at least 16 random
+// bytes, base64-encoded) can be given for namespaces named in requests along
+// with an optional default secret used when no namespace is named. Since the
+// derived secret key is held in the reference losing a secret does not lose
+// access to anything already stored.
+type ConvergenceConfig struct {
+	// File containing the base64-encoded default secret
+	SecretFile string `toml:",omitempty"`
+	// Environment variable containing the base64-encoded default secret
+	SecretEnv string `toml:",omitempty"`
+	// Secrets by namespace
+	Namespaces map[string]*ConvergenceSecretConfig `toml:",omitempty"`
+}
+
+type ConvergenceSecretConfig struct {
+	// File containing the base64-encoded secret
+	SecretFile string `toml:",omitempty"`
+	// Environment variable containing the base64-encoded secret
+	SecretEnv string `toml:",omitempty"`
+}
+
+func NewConvergenceConfig(secretFile string) *ConvergenceConfig {
+	return &ConvergenceConfig{
+		SecretFile: secretFile,
+	}
+}
+
+// Get the convergence secrets by namespace with the default secret (if one is
+// configured) under the empty namespace
+func (cc *ConvergenceConfig) Secrets() (map[string][]byte, error) {
+	secrets := make(map[string][]byte, len(cc.Namespaces)+1)
+	if cc.SecretFile != "" || cc.SecretEnv != "" {
+		secret, err := readConvergenceSecret("default convergence secret",
+			cc.SecretFile, cc.SecretEnv)
+		if err != nil {
+			return nil, err
+		}
+		secrets[""] = secret
+	}
+	for namespace, csc := range cc.Namespaces {
+		if namespace == "" {
+			return nil, fmt.Errorf("Convergence secret namespaces must not be " +
+				"empty, configure the default secret with SecretFile or SecretEnv")
+		}
+		secret, err := readConvergenceSecret(fmt.Sprintf("convergence secret for "+
+			"namespace '%s'", namespace), csc.SecretFile, csc.SecretEnv)
+		if err != nil {
+			return nil, err
+		}
+		secrets[namespace] = secret
+	}
+	return secrets, nil
+}
+
+func readConvergenceSecret(description, file, env string) ([]byte, error) {
+	secret, err := readBase64Secret(description, file, env)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) < MinConvergenceSecretLength {
+		return nil, fmt.Errorf("The %s must be at least %v bytes long but is %v "+
+			"bytes long", description, MinConvergenceSecretLength, len(secret))
+	}
+	return secret, nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvergenceConfig(t *testing.T) {
+	storageConfig := DefaultMemoryConfig()
+	storageConfig.Convergence = &ConvergenceConfig{
+		SecretEnv: "HOARD_TEST_CONVERGENCE_SECRET",
+		Namespaces: map[string]*ConvergenceSecretConfig{
+			"alice": {SecretEnv: "HOARD_TEST_CONVERGENCE_SECRET_ALICE"},
+		},
+	}
+	assertStorageConfigSerialisation(t, storageConfig)
+
+	_, err := storageConfig.Convergence.Secrets()
+	assert.Error(t, err)
+
+	os.Setenv("HOARD_TEST_CONVERGENCE_SECRET", "c2VjcmV0c2VjcmV0c2VjcmV0")
+	defer os.Unsetenv("HOARD_TEST_CONVERGENCE_SECRET")
+	os.Setenv("HOARD_TEST_CONVERGENCE_SECRET_ALICE", "YWxpY2VhbGljZWFsaWNlYWxpY2U=")
+	defer os.Unsetenv("HOARD_TEST_CONVERGENCE_SECRET_ALICE")
+	secrets, err := storageConfig.Convergence.Secrets()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"":      []byte("secretsecretsecret"),
+		"alice": []byte("alicealicealicealice"),
+	}, secrets)
+
+	// The default secret is optional
+	storageConfig.Convergence.SecretEnv = ""
+	secrets, err = storageConfig.Convergence.Secrets()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"alice": []byte("alicealicealicealice")},
+		secrets)
+
+	// Secrets must be long enough not to be guessed
+	os.Setenv("HOARD_TEST_CONVERGENCE_SECRET_ALICE", "YWxpY2VhbGljZWFsaWNl")
+	_, err = storageConfig.Convergence.Secrets()
+	assert.Error(t, err)
+
+	storageConfig.Convergence.Namespaces[""] = &ConvergenceSecretConfig{
+		SecretEnv: "HOARD_TEST_CONVERGENCE_SECRET",
+	}
+	_, err = storageConfig.Convergence.Secrets()
+	assert.Error(t, err)
+}
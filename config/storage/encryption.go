@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"github.com/monax/hoard/core/storage"
+)
+
+// The presence of an AtRestEncryption section in a storage config causes data
+// to be encrypted again with a master key before it reaches the backend (see
+// storage.NewEncryptedStore). Exactly one source for the keyring JSON (see
+// storage.KeyringFromJSON) should be given.
+type AtRestEncryptionConfig struct {
+	// File containing the keyring
+	KeyringFile string
+	// Environment variable containing the keyring
+	KeyringEnv string
+}
+
+func NewAtRestEncryptionConfig(keyringFile string) *AtRestEncryptionConfig {
+	return &AtRestEncryptionConfig{
+		KeyringFile: keyringFile,
+	}
+}
+
+func (arec *AtRestEncryptionConfig) Keyring() (*storage.Keyring, error) {
+	keyringJSON, err := readSecret("at-rest encryption keyring",
+		arec.KeyringFile, arec.KeyringEnv)
+	if err != nil {
+		return nil, err
+	}
+	return storage.KeyringFromJSON([]byte(keyringJSON))
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtRestEncryptionConfig(t *testing.T) {
+	storageConfig := DefaultMemoryConfig()
+	storageConfig.AtRestEncryption = &AtRestEncryptionConfig{
+		KeyringEnv: "HOARD_TEST_KEYRING",
+	}
+	assertStorageConfigSerialisation(t, storageConfig)
+
+	os.Setenv("HOARD_TEST_KEYRING", `{"CurrentKeyID": 1, "Keys": {
+		"1": "MTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTE="}}`)
+	defer os.Unsetenv("HOARD_TEST_KEYRING")
+	store, err := StoreFromStorageConfig(storageConfig, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "encryptedStore[currentKeyID=1]<memoryStore>", store.Name())
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/monax/hoard/core/storage"
+)
+
+// Wraps another storage backend to inject faults for testing applications
+// built on Hoard. Not for production use.
+type FaultConfig struct {
+	// Seed for the source of faults so that runs are repeatable
+	Seed int64
+	// The backend into which faults are injected
+	FaultStorage *StorageConfig
+	// The first rule matching each call determines its faults
+	FaultRules []*FaultRuleConfig
+}
+
+// See storage.FaultRule. Latencies are strings parsed by time.ParseDuration.
+type FaultRuleConfig struct {
+	// Regular expression matched against addresses encoded with the fault
+	// store's AddressEncoding
+	AddressPattern string
+	Methods        []string
+	ErrorRate      float64
+	// One of "uniform" (the default) or "exponential"
+	LatencyDistribution string `toml:",omitempty"`
+	MinLatency          string
+	MaxLatency          string
+	MeanLatency         string `toml:",omitempty"`
+	TruncateRate        float64
+	BitFlipRate         float64
+}
+
+func NewFaultConfig(addressEncoding string, seed int64,
+	faultStorage *StorageConfig, faultRules ...*FaultRuleConfig) *StorageConfig {
+	return &StorageConfig{
+		StorageType:     Fault,
+		AddressEncoding: addressEncoding,
+		FaultConfig: &FaultConfig{
+			Seed:         seed,
+			FaultStorage: faultStorage,
+			FaultRules:   faultRules,
+		},
+	}
+}
+
+func DefaultFaultConfig() *StorageConfig {
+	return NewFaultConfig(DefaultAddressEncodingName, 1, DefaultMemoryConfig(),
+		&FaultRuleConfig{
+			Methods:    []string{"Get"},
+			ErrorRate:  0.05,
+			MinLatency: "1ms",
+			MaxLatency: "50ms",
+		},
+		&FaultRuleConfig{
+			// Base64-encoded SHA256 multihash addresses begin 'EiA' for about a
+			// quarter of blobs
+			AddressPattern: "^EiA",
+			BitFlipRate:    0.01,
+		})
+}
+
+func (frc *FaultRuleConfig) FaultRule() (*storage.FaultRule, error) {
+	rule := &storage.FaultRule{
+		Methods:      frc.Methods,
+		ErrorRate:    frc.ErrorRate,
+		TruncateRate: frc.TruncateRate,
+		BitFlipRate:  frc.BitFlipRate,
+	}
+	err := checkRate("ErrorRate", frc.ErrorRate)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRate("TruncateRate", frc.TruncateRate)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRate("BitFlipRate", frc.BitFlipRate)
+	if err != nil {
+		return nil, err
+	}
+	if frc.AddressPattern != "" {
+		rule.AddressPattern, err = regexp.Compile(frc.AddressPattern)
+		if err != nil {
+			return nil, fmt.Errorf("Could not compile fault AddressPattern: %s",
+				err)
+		}
+	}
+	switch frc.LatencyDistribution {
+	case "", storage.UniformLatency, storage.ExponentialLatency:
+		rule.LatencyDistribution = frc.LatencyDistribution
+	default:
+		return nil, fmt.Errorf("Fault LatencyDistribution '%s' is not one of "+
+			"%s or %s", frc.LatencyDistribution, storage.UniformLatency,
+			storage.ExponentialLatency)
+	}
+	rule.MinLatency, err = parseLatency("MinLatency", frc.MinLatency)
+	if err != nil {
+		return nil, err
+	}
+	rule.MaxLatency, err = parseLatency("MaxLatency", frc.MaxLatency)
+	if err != nil {
+		return nil, err
+	}
+	rule.MeanLatency, err = parseLatency("MeanLatency", frc.MeanLatency)
+	if err != nil {
+		return nil, err
+	}
+	if rule.MaxLatency > 0 && rule.MinLatency > rule.MaxLatency {
+		return nil, fmt.Errorf("Fault MinLatency %s is greater than "+
+			"MaxLatency %s", rule.MinLatency, rule.MaxLatency)
+	}
+	if rule.LatencyDistribution == storage.ExponentialLatency &&
+		rule.MeanLatency <= 0 {
+		return nil, fmt.Errorf("Fault MeanLatency must be set for %s latency",
+			storage.ExponentialLatency)
+	}
+	return rule, nil
+}
+
+func parseLatency(name, latency string) (time.Duration, error) {
+	if latency == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(latency)
+	if err != nil {
+		return 0, fmt.Errorf("Could not parse fault %s: %s", name, err)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("Fault %s must not be negative", name)
+	}
+	return duration, nil
+}
+
+func checkRate(name string, rate float64) error {
+	// Written so that NaN is rejected too
+	if !(rate >= 0 && rate <= 1) {
+		return fmt.Errorf("Fault %s must be between 0 and 1 but is %v", name,
+			rate)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/monax/hoard/core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultFaultConfig(t *testing.T) {
+	assertStorageConfigSerialisation(t, DefaultFaultConfig())
+	store, err := StoreFromStorageConfig(DefaultFaultConfig(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "faultStore[seed=1,rules=2]<memoryStore>", store.Name())
+
+	// The example address pattern should match some but not all addresses
+	rule, err := DefaultFaultConfig().FaultConfig.FaultRules[1].FaultRule()
+	assert.NoError(t, err)
+	hashAlgorithm, err := storage.GetHashAlgorithm(storage.DefaultHashName)
+	assert.NoError(t, err)
+	addressEncoding, err := storage.GetAddressEncoding(DefaultAddressEncodingName)
+	assert.NoError(t, err)
+	matches := 0
+	for i := 0; i < 64; i++ {
+		address := hashAlgorithm.Addresser()([]byte{byte(i)})
+		if rule.AddressPattern.MatchString(addressEncoding.EncodeToString(address)) {
+			matches++
+		}
+	}
+	assert.True(t, matches > 0 && matches < 64, "matched %v", matches)
+}
+
+func TestFaultRuleConfigLatency(t *testing.T) {
+	rule, err := (&FaultRuleConfig{MinLatency: "5ms"}).FaultRule()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Millisecond, rule.MinLatency)
+
+	rule, err = (&FaultRuleConfig{
+		LatencyDistribution: "exponential",
+		MinLatency:          "1ms",
+		MeanLatency:         "10ms",
+	}).FaultRule()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Millisecond, rule.MeanLatency)
+
+	for _, invalid := range []*FaultRuleConfig{
+		{MinLatency: "50ms", MaxLatency: "10ms"},
+		{MinLatency: "-1ms"},
+		{LatencyDistribution: "exponential"},
+		{LatencyDistribution: "normal"},
+		{ErrorRate: 1.5},
+		{TruncateRate: -0.1},
+		{BitFlipRate: math.NaN()},
+	} {
+		_, err = invalid.FaultRule()
+		assert.Error(t, err, "%#v", invalid)
+	}
+}
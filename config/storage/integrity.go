@@ -0,0 +1,18 @@
+package storage
+
+// The presence of an Integrity section in a storage config causes data read
+// from the store to be checked against its address before it is returned
+type IntegrityConfig struct {
+	// Optional store from which to fetch replacements for corrupted data
+	RepairStorage *StorageConfig
+	// Optional store into which to move corrupted data for later inspection
+	QuarantineStorage *StorageConfig
+}
+
+func NewIntegrityConfig(repairStorage,
+	quarantineStorage *StorageConfig) *IntegrityConfig {
+	return &IntegrityConfig{
+		RepairStorage:     repairStorage,
+		QuarantineStorage: quarantineStorage,
+	}
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrityConfig(t *testing.T) {
+	storageConfig := DefaultFileSystemConfig()
+	storageConfig.Integrity = NewIntegrityConfig(DefaultMemoryConfig(),
+		NewFileSystemConfig(DefaultAddressEncodingName, "/tmp/hoard-quarantine"))
+	assertStorageConfigSerialisation(t, storageConfig)
+
+	storageConfig = DefaultMemoryConfig()
+	storageConfig.Integrity = NewIntegrityConfig(DefaultMemoryConfig(), nil)
+	store, err := StoreFromStorageConfig(storageConfig, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "verifyingStore<memoryStore>", store.Name())
+}
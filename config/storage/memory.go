@@ -1,9 +1,45 @@
 package storage
 
+import (
+	"fmt"
+	"time"
+)
+
+type MemoryConfig struct {
+	// If set the memory store is loaded from this file at startup and written
+	// to it on shutdown, on 'hoard snapshot', and every SnapshotInterval
+	SnapshotFile string
+	// How often to write snapshots in the background (e.g. "5m"), empty for
+	// only on shutdown or request
+	SnapshotInterval string
+}
+
 func NewMemoryConfig(addressEncoding string) *StorageConfig {
 	return NewStorageConfig(Memory, addressEncoding)
 }
 
+func NewSnapshottingMemoryConfig(addressEncoding, snapshotFile,
+	snapshotInterval string) *StorageConfig {
+	storageConfig := NewMemoryConfig(addressEncoding)
+	storageConfig.MemoryConfig = &MemoryConfig{
+		SnapshotFile:     snapshotFile,
+		SnapshotInterval: snapshotInterval,
+	}
+	return storageConfig
+}
+
 func DefaultMemoryConfig() *StorageConfig {
 	return NewMemoryConfig(DefaultAddressEncodingName)
 }
+
+func (mc *MemoryConfig) Interval() (time.Duration, error) {
+	if mc.SnapshotInterval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(mc.SnapshotInterval)
+	if err != nil {
+		return 0, fmt.Errorf("Could not parse SnapshotInterval in memory "+
+			"config: %s", err)
+	}
+	return interval, nil
+}
@@ -1,7 +1,36 @@
 package storage
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/monax/hoard/core/storage"
+	"github.com/stretchr/testify/assert"
+)
 
 func TestDefaultMemoryConfig(t *testing.T) {
 	assertStorageConfigSerialisation(t, DefaultMemoryConfig())
 }
+
+func TestSnapshottingMemoryConfig(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "memory_config_test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	storageConfig := NewSnapshottingMemoryConfig(DefaultAddressEncodingName,
+		path.Join(tempDir, "hoard.snapshot"), "1h")
+	assertStorageConfigSerialisation(t, storageConfig)
+	store, err := StoreFromStorageConfig(storageConfig, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put([]byte("address"), []byte("data")))
+	assert.NoError(t, storage.Close(store))
+
+	store, err = StoreFromStorageConfig(storageConfig, nil)
+	assert.NoError(t, err)
+	data, err := store.Get([]byte("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	assert.NoError(t, storage.Close(store))
+}
@@ -0,0 +1,26 @@
+package storage
+
+type PluginConfig struct {
+	// Executable to launch as a storage plugin, looked up in PATH if it
+	// contains no path separators
+	Command string
+	Args    []string
+	// Extra environment variables passed to the plugin as 'KEY=value' pairs
+	Env []string
+}
+
+func NewPluginConfig(addressEncoding, command string, args ...string) *StorageConfig {
+	return &StorageConfig{
+		StorageType:     Plugin,
+		AddressEncoding: addressEncoding,
+		PluginConfig: &PluginConfig{
+			Command: command,
+			Args:    args,
+		},
+	}
+}
+
+func DefaultPluginConfig() *StorageConfig {
+	return NewPluginConfig(DefaultAddressEncodingName, "hoard-plugin-fs",
+		"--root", "/var/lib/hoard")
+}
@@ -0,0 +1,7 @@
+package storage
+
+import "testing"
+
+func TestDefaultPluginConfig(t *testing.T) {
+	assertStorageConfigSerialisation(t, DefaultPluginConfig())
+}
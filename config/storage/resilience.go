@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/monax/hoard/core/storage"
+)
+
+// Policies for calling a storage backend, see storage.ResiliencePolicy.
+// Durations are strings parsed by time.ParseDuration (e.g. "200ms", "30s") and
+// zero values disable the corresponding policy.
+type ResilienceConfig struct {
+	MaxAttempts      int
+	InitialBackoff   string
+	MaxBackoff       string
+	Timeout          string
+	MaxConcurrency   int
+	BreakerThreshold int
+	BreakerCooldown  string
+}
+
+func DefaultResilienceConfig() *ResilienceConfig {
+	return &ResilienceConfig{
+		MaxAttempts:      3,
+		InitialBackoff:   "100ms",
+		MaxBackoff:       "2s",
+		Timeout:          "30s",
+		MaxConcurrency:   64,
+		BreakerThreshold: 10,
+		BreakerCooldown:  "30s",
+	}
+}
+
+func (rc *ResilienceConfig) Policy() (storage.ResiliencePolicy, error) {
+	policy := storage.ResiliencePolicy{
+		MaxAttempts:      rc.MaxAttempts,
+		MaxConcurrency:   rc.MaxConcurrency,
+		BreakerThreshold: rc.BreakerThreshold,
+	}
+	durations := []struct {
+		name     string
+		value    string
+		duration *time.Duration
+	}{
+		{"InitialBackoff", rc.InitialBackoff, &policy.InitialBackoff},
+		{"MaxBackoff", rc.MaxBackoff, &policy.MaxBackoff},
+		{"Timeout", rc.Timeout, &policy.Timeout},
+		{"BreakerCooldown", rc.BreakerCooldown, &policy.BreakerCooldown},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return policy, fmt.Errorf("Could not parse %s in resilience "+
+				"config: %s", d.name, err)
+		}
+		*d.duration = duration
+	}
+	return policy, nil
+}
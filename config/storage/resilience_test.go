@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResilienceConfig(t *testing.T) {
+	storageConfig := DefaultMemoryConfig()
+	storageConfig.Resilience = DefaultResilienceConfig()
+	assertStorageConfigSerialisation(t, storageConfig)
+
+	policy, err := storageConfig.Resilience.Policy()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, policy.MaxAttempts)
+	assert.Equal(t, 100*time.Millisecond, policy.InitialBackoff)
+	assert.Equal(t, 30*time.Second, policy.BreakerCooldown)
+
+	store, err := StoreFromStorageConfig(storageConfig, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, store.Name(), "resilientStore")
+
+	storageConfig.Resilience.Timeout = "soon"
+	_, err = StoreFromStorageConfig(storageConfig, nil)
+	assert.Error(t, err)
+}
@@ -6,7 +6,9 @@ import (
 	"os/user"
 	"path"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/monax/hoard/core/storage"
 )
 
 type ProviderName string
@@ -18,9 +20,27 @@ const (
 )
 
 type S3Config struct {
-	Bucket                   string
-	Prefix                   string
-	Region                   string
+	Bucket string
+	Prefix string
+	Region string
+	// Set to target an S3-compatible service such as MinIO or Ceph, for
+	// example "localhost:9000" or "https://s3.example.com"
+	Endpoint string
+	// Address buckets as <endpoint>/<bucket> rather than <bucket>.<endpoint>
+	// as most S3-compatible services require
+	ForcePathStyle bool
+	// Use plain HTTP for an Endpoint without a scheme
+	DisableSSL bool
+	// One of "AES256" (SSE-S3) or "aws:kms" (SSE-KMS) to request server-side
+	// encryption
+	ServerSideEncryption string
+	// KMS key id for SSE-KMS, the account default key is used if empty
+	SSEKMSKeyID  string
+	StorageClass string
+	// Size in bytes of each part of multipart uploads (minimum 5 MiB)
+	PartSize int64
+	// Number of parts of a multipart upload to upload in parallel
+	UploadConcurrency        int
 	CredentialsProviderChain []*CredentialsProviderConfig
 }
 
@@ -30,6 +50,33 @@ type CredentialsProviderConfig struct {
 	*StaticProviderConfig
 }
 
+func (s3c *S3Config) AWSConfig() *aws.Config {
+	awsConfig := aws.NewConfig()
+	if s3c.Region != "" {
+		awsConfig.Region = aws.String(s3c.Region)
+	}
+	if s3c.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(s3c.Endpoint)
+	}
+	if s3c.ForcePathStyle {
+		awsConfig.S3ForcePathStyle = aws.Bool(true)
+	}
+	if s3c.DisableSSL {
+		awsConfig.DisableSSL = aws.Bool(true)
+	}
+	return awsConfig
+}
+
+func (s3c *S3Config) UploadOptions() *storage.S3UploadOptions {
+	return &storage.S3UploadOptions{
+		ServerSideEncryption: s3c.ServerSideEncryption,
+		SSEKMSKeyID:          s3c.SSEKMSKeyID,
+		StorageClass:         s3c.StorageClass,
+		PartSize:             s3c.PartSize,
+		Concurrency:          s3c.UploadConcurrency,
+	}
+}
+
 // Almost the same a credentials.Value
 type StaticProviderConfig struct {
 	AccessKeyID     string
@@ -176,5 +223,6 @@ func DefaultS3Config() *StorageConfig {
 	if err != nil {
 		panic(fmt.Errorf("Could not generate example config: %s", err))
 	}
+	s3c.Resilience = DefaultResilienceConfig()
 	return s3c
 }
@@ -6,6 +6,8 @@ import (
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/storage/s3test"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,3 +38,33 @@ func TestAWSCredentialsFromConfig(t *testing.T) {
 func TestDefaultS3Config(t *testing.T) {
 	assertStorageConfigSerialisation(t, DefaultS3Config())
 }
+
+func TestS3ConfigFakeServer(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+
+	storageConfig, err := NewS3Config(DefaultAddressEncodingName, "bucket",
+		"prefix", s3test.Region, &credentials.StaticProvider{
+			Value: credentials.Value{
+				AccessKeyID:     "id",
+				SecretAccessKey: "secret",
+			},
+		})
+	assert.NoError(t, err)
+	storageConfig.Endpoint = server.URL
+	storageConfig.ForcePathStyle = true
+	storageConfig.StorageClass = "REDUCED_REDUNDANCY"
+
+	store, err := StoreFromStorageConfig(storageConfig, log.NewNopLogger())
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put([]byte("address"), []byte("data")))
+	data, err := store.Get([]byte("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	keys := server.Keys("bucket")
+	if assert.Len(t, keys, 1) {
+		assert.Equal(t, "REDUCED_REDUNDANCY",
+			server.Object("bucket", keys[0]).Header.Get("X-Amz-Storage-Class"))
+	}
+}
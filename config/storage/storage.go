@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/go-kit/kit/log"
 	"github.com/monax/hoard/core/storage"
+	"github.com/monax/hoard/core/storage/plugin"
 )
 
 const DefaultAddressEncodingName = storage.Base64EncodingName
@@ -25,6 +26,9 @@ const (
 	Filesystem  StorageType = "filesystem"
 	S3          StorageType = "s3"
 	IPFS        StorageType = "ipfs"
+	Plugin      StorageType = "plugin"
+	// Injects faults into another backend for testing
+	Fault StorageType = "fault"
 )
 
 type StorageConfig struct {
@@ -32,12 +36,29 @@ type StorageConfig struct {
 	StorageType StorageType
 	// Address encoding name
 	AddressEncoding string
+	// Name of the hash algorithm from which addresses of new blobs are derived
+	// as multihashes (defaults to sha2-256)
+	HashAlgorithm string `toml:",omitempty"`
 	// Embedding a pointer to each type of config struct allows us to access the
 	// relevant one, while at the same time those that are left as nil will be
 	// omitted from being serialised.
+	*MemoryConfig
 	*FileSystemConfig
 	*S3Config
 	*IPFSConfig
+	*PluginConfig
+	*FaultConfig
+	// Optional retry, timeout, concurrency, and circuit breaking policies
+	Resilience *ResilienceConfig
+	// Optional encryption of data at rest with master keys held by the daemon
+	AtRestEncryption *AtRestEncryptionConfig
+	// Optional keyed blinding of the addresses used in the backend
+	Blinding *BlindingConfig
+	// Optional verification of data read from the store
+	Integrity *IntegrityConfig
+	// Optional secrets mixed into the secret keys of blobs to limit
+	// deduplication and confirmation of stored plaintexts to a trust domain
+	Convergence *ConvergenceConfig
 }
 
 func NewStorageConfig(storageType StorageType, addressEncoding string) *StorageConfig {
@@ -50,6 +71,68 @@ func NewStorageConfig(storageType StorageType, addressEncoding string) *StorageC
 func StoreFromStorageConfig(storageConfig *StorageConfig,
 	logger log.Logger) (storage.Store, error) {
 
+	store, err := backendFromStorageConfig(storageConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	if storageConfig.Resilience != nil {
+		policy, err := storageConfig.Resilience.Policy()
+		if err != nil {
+			return nil, err
+		}
+		store = storage.NewResilientStore(store, policy, logger)
+	}
+	if storageConfig.AtRestEncryption != nil {
+		keyring, err := storageConfig.AtRestEncryption.Keyring()
+		if err != nil {
+			return nil, err
+		}
+		store = storage.NewEncryptedStore(store, keyring)
+	}
+	if storageConfig.Blinding != nil {
+		secret, err := storageConfig.Blinding.Secret()
+		if err != nil {
+			return nil, err
+		}
+		store, err = storage.NewBlindingStore(store, secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if storageConfig.Integrity != nil {
+		return verifyingStoreFromIntegrityConfig(store,
+			storageConfig.Integrity, logger)
+	}
+	return store, nil
+}
+
+func verifyingStoreFromIntegrityConfig(store storage.Store,
+	integrityConfig *IntegrityConfig, logger log.Logger) (storage.Store, error) {
+
+	var repairStore, quarantineStore storage.Store
+	var err error
+	if integrityConfig.RepairStorage != nil {
+		repairStore, err = StoreFromStorageConfig(integrityConfig.RepairStorage,
+			logger)
+		if err != nil {
+			return nil, fmt.Errorf("Could not configure repair storage: %s", err)
+		}
+	}
+	if integrityConfig.QuarantineStorage != nil {
+		quarantineStore, err = StoreFromStorageConfig(
+			integrityConfig.QuarantineStorage, logger)
+		if err != nil {
+			return nil, fmt.Errorf("Could not configure quarantine storage: %s",
+				err)
+		}
+	}
+	return storage.NewVerifyingStore(store, storage.MatchesAddress,
+		repairStore, quarantineStore, logger), nil
+}
+
+func backendFromStorageConfig(storageConfig *StorageConfig,
+	logger log.Logger) (storage.Store, error) {
+
 	addressEncoding, err := storage.GetAddressEncoding(storageConfig.AddressEncoding)
 	if err != nil {
 		return nil, err
@@ -57,7 +140,16 @@ func StoreFromStorageConfig(storageConfig *StorageConfig,
 
 	switch storageConfig.StorageType {
 	case Memory, Unspecified:
-		return storage.NewMemoryStore(), nil
+		mc := storageConfig.MemoryConfig
+		if mc == nil || mc.SnapshotFile == "" {
+			return storage.NewMemoryStore(), nil
+		}
+		interval, err := mc.Interval()
+		if err != nil {
+			return nil, err
+		}
+		return storage.NewSnapshottingMemoryStore(mc.SnapshotFile, interval,
+			logger)
 	case Filesystem:
 		fsc := storageConfig.FileSystemConfig
 		if fsc == nil {
@@ -81,21 +173,40 @@ func StoreFromStorageConfig(storageConfig *StorageConfig,
 			return nil, fmt.Errorf("Could not create credentials: %s", err)
 		}
 
-		var region *string
-		if s3c.Region != "" {
-			region = aws.String(s3c.Region)
-		}
-
-		awsConfig := &aws.Config{
-			Credentials: creds,
-			Region:      region,
-			Logger: aws.LoggerFunc(func(keyvals ...interface{}) {
-				logger.Log(keyvals...)
-			}),
-		}
+		awsConfig := s3c.AWSConfig()
+		awsConfig.Credentials = creds
+		awsConfig.Logger = aws.LoggerFunc(func(keyvals ...interface{}) {
+			logger.Log(keyvals...)
+		})
 
 		return storage.NewS3Store(s3c.Bucket, s3c.Prefix, addressEncoding,
-			awsConfig, logger)
+			awsConfig, s3c.UploadOptions(), logger)
+	case Plugin:
+		pc := storageConfig.PluginConfig
+		if pc == nil || pc.Command == "" {
+			return nil, errors.New("Plugin configuration including a Command " +
+				"must be supplied to use a plugin storage backend")
+		}
+		return plugin.NewPluginStore(pc.Command, pc.Args, pc.Env, logger)
+	case Fault:
+		fc := storageConfig.FaultConfig
+		if fc == nil || fc.FaultStorage == nil {
+			return nil, errors.New("Fault configuration including " +
+				"FaultStorage must be supplied to use the fault storage backend")
+		}
+		store, err := StoreFromStorageConfig(fc.FaultStorage, logger)
+		if err != nil {
+			return nil, err
+		}
+		rules := make([]*storage.FaultRule, len(fc.FaultRules))
+		for i, frc := range fc.FaultRules {
+			rules[i], err = frc.FaultRule()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return storage.NewFaultStore(store, addressEncoding, fc.Seed, rules,
+			logger), nil
 	default:
 		return nil, fmt.Errorf("Did not recognise storage type '%s'",
 			storageConfig.StorageType)
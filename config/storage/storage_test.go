@@ -20,3 +20,20 @@ func assertStorageConfigSerialisation(t *testing.T,
 	assert.NotEmpty(t, tomlString)
 	assert.Equal(t, tomlString, storageConfigOut.TOMLString())
 }
+
+func TestHashAlgorithmConfig(t *testing.T) {
+	storageConfig := NewMemoryConfig(DefaultAddressEncodingName)
+	assert.NotContains(t, storageConfig.TOMLString(), "HashAlgorithm")
+	storageConfig.HashAlgorithm = "blake2b-256"
+	assertStorageConfigSerialisation(t, storageConfig)
+
+	storageConfig, err := ConfigFromString(`
+StorageType = "memory"
+AddressEncoding = "multibase-base32"
+HashAlgorithm = "sha3-256"
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha3-256", storageConfig.HashAlgorithm)
+	_, err = StoreFromStorageConfig(storageConfig, nil)
+	assert.NoError(t, err)
+}
@@ -1,24 +1,27 @@
 package encryption
 
 import (
-	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"hash"
 )
 
-type BlockCipherMaker func(key []byte) (cipher.Block, error)
-
 type EncryptedBlob interface {
 	SecretKey() []byte
 	EncryptedData() []byte
+	// The name of the cipher suite used to encrypt the blob
+	Suite() string
 }
 
 type encryptedBlob struct {
 	secretKey     []byte
 	encryptedData []byte
+	suite         string
 }
 
 func (blob *encryptedBlob) SecretKey() []byte {
@@ -29,6 +32,34 @@ func (blob *encryptedBlob) EncryptedData() []byte {
 	return blob.encryptedData
 }
 
+func (blob *encryptedBlob) Suite() string {
+	return blob.suite
+}
+
+// Options for encrypting a blob, the zero value gives the defaults
+type Options struct {
+	// The name of the cipher suite to encrypt with, if empty DefaultSuite is used
+	Suite string
+	// Prefix the ciphertext with a Header recording the parameters it was
+	// encrypted with
+	Header bool
+	// A secret mixed into the secret key with HMAC-SHA256 so that only holders
+	// of the secret can derive the secret key (and so address) of a plaintext.
+	// Not needed to decrypt.
+	ConvergenceSecret []byte
+	// Encrypt with a random secret key rather than one derived from the
+	// plaintext so identical plaintexts produce unrelated ciphertexts and are
+	// never deduplicated. ConvergenceSecret is ignored.
+	Random bool
+	// The name of the padding scheme applied to the salted plaintext before
+	// encryption to hide its exact size, if empty no padding is applied
+	Padding string
+	// Caller-supplied context (such as a tenant identifier or contract address)
+	// authenticated as additional data so the blob only decrypts when the same
+	// context is provided
+	Context []byte
+}
+
 // Encrypt data convergently by using a securely generated deterministic
 // key that is a hash of the plaintext (data/blob) itself. Allows for
 // deduplication of ciphertexts and recovery of keys from plaintext alone.
@@ -42,14 +73,117 @@ func Encrypt(data, salt []byte) (EncryptedBlob, error) {
 	// The SHA 256 hasher will be used to generate the secret key for AES. Since
 	// the AES cipher is parameterised by the length of the secret key in this case
 	// with the 32 byte key from SHA 256 we will get a AES 256 block cipher.
-	return encryptConvergent(sha256.New(), aes.NewCipher, salinate(data, salt),
-		additionalDataForSalt(salt))
+	return EncryptWithOptions(sha256.New, data, salt, Options{})
+}
+
+// Encrypt as Encrypt but with the options provided and deriving the secret key
+// with the hash function returned by newHash, which must produce a digest that
+// is a valid key length for the suite
+func EncryptWithOptions(newHash func() hash.Hash, data, salt []byte,
+	options Options) (EncryptedBlob, error) {
+	suite, err := GetSuite(options.Suite)
+	if err != nil {
+		return nil, err
+	}
+	padding, err := GetPadding(options.Padding)
+	if err != nil {
+		return nil, err
+	}
+	var header []byte
+	if options.Header {
+		header = NewHeader(suite).Bytes()
+	}
+	// Pad before deriving the secret key so that the padded and unpadded
+	// plaintexts are never encrypted under the same key
+	plaintext := padding.Pad(salinate(data, salt))
+	var blob *encryptedBlob
+	additionalData := additionalData(header, salt, padding, options.Context)
+	if options.Random {
+		blob, err = encryptRandom(newHash().Size(), suite.NewAEAD, plaintext,
+			additionalData)
+	} else {
+		blob, err = encryptConvergent(newHash(), options.ConvergenceSecret,
+			options.Context, suite.NewAEAD, plaintext, additionalData)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		blob.encryptedData = append(header, blob.encryptedData...)
+	}
+	blob.suite = suite.Name
+	return blob, nil
 }
 
 // Decrypt data that was deterministically encrypted with the provided salt
 func Decrypt(secretKey, encryptedData, salt []byte) ([]byte, error) {
-	data, err := decryptConvergent(aes.NewCipher, secretKey, encryptedData,
-		additionalDataForSalt(salt))
+	return DecryptWithOptions(secretKey, encryptedData, salt, Options{})
+}
+
+// Decrypt data that was encrypted with the provided salt and options (of which
+// only Suite, Padding, and Context are needed). If the ciphertext has a Header
+// it is decrypted with the suite it records, which must match options.Suite
+// unless that is empty, so that a header cannot downgrade the suite a reference
+// expects (for instance to strip a key commitment).
+func DecryptWithOptions(secretKey, encryptedData, salt []byte,
+	options Options) ([]byte, error) {
+	padding, err := GetPadding(options.Padding)
+	if err != nil {
+		return nil, err
+	}
+	var headerErr error
+	header, err := ParseHeader(encryptedData)
+	if err == nil {
+		if options.Suite != "" && header.Suite != options.Suite {
+			headerErr = fmt.Errorf("Ciphertext header records suite '%s' but "+
+				"suite '%s' was expected", header.Suite, options.Suite)
+		} else {
+			data, err := decryptWithHeader(header, secretKey, encryptedData, salt,
+				padding, options.Context)
+			if err == nil {
+				return data, nil
+			}
+			headerErr = err
+		}
+		// A ciphertext without a header may begin with what looks like one so
+		// fall through and try to decrypt it as a headerless ciphertext
+	}
+	suite, err := GetSuite(options.Suite)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decryptConvergent(suite.NewAEAD, secretKey, encryptedData,
+		additionalData(nil, salt, padding, options.Context))
+	if err != nil {
+		if headerErr != nil {
+			return nil, headerErr
+		}
+		return nil, err
+	}
+	return unpadAndDesalinate(data, salt, padding)
+}
+
+func decryptWithHeader(header *Header, secretKey, encryptedData, salt []byte,
+	padding *Padding, context []byte) ([]byte, error) {
+	if header.SegmentSize != UnsegmentedSegmentSize {
+		return nil, fmt.Errorf("Ciphertext header has segment size %v but "+
+			"segmented ciphertexts are not supported", header.SegmentSize)
+	}
+	suite, err := GetSuite(header.Suite)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decryptConvergent(suite.NewAEAD, secretKey,
+		encryptedData[HeaderLength:], additionalData(encryptedData[:HeaderLength],
+			salt, padding, context))
+	if err != nil {
+		return nil, err
+	}
+	return unpadAndDesalinate(data, salt, padding)
+}
+
+func unpadAndDesalinate(data, salt []byte, padding *Padding) ([]byte, error) {
+	data, err := padding.Unpad(data)
 	if err != nil {
 		return nil, err
 	}
@@ -57,9 +191,12 @@ func Decrypt(secretKey, encryptedData, salt []byte) ([]byte, error) {
 }
 
 // Encrypt plaintext convergently by using a secure hash of the plaintext as the
-// secret key to the block cipher produced by blockCipherMaker that will be used
-// in Galois Counter Mode as a stream cipher with salt used as additional
-// authenticated data.
+// secret key to the AEAD produced by aeadMaker with salt used as additional
+// authenticated data. If a convergenceSecret is provided the secret key is
+// instead the HMAC-SHA256 of the hash keyed with the secret. If a context is
+// provided it is mixed into the secret key (as the message of HMAC-SHA256 keyed
+// with the key derived so far) so that blobs differing only in their context
+// (which is part of the additional data) never share a key and nonce.
 //
 // Note that this deterministic encryption is by design not secure under a chosen
 // plaintext attack. However it can be used in this mode by prefixing a random,
@@ -75,28 +212,56 @@ func Decrypt(secretKey, encryptedData, salt []byte) ([]byte, error) {
 // remaining portion (such as an account number) to query whether a particular
 // blob is stored. We actually want this behaviour to deduplicate and locate
 // encrypted blobs. However if you want to distinguish copies of a plaintext or
-// hide them add a random salt as above.
-func encryptConvergent(hasher hash.Hash, blockCipherMaker BlockCipherMaker,
-	plaintext, additionalData []byte) (EncryptedBlob, error) {
+// hide them add a random salt as above, or a convergence secret to confine
+// these properties to those holding the secret.
+func encryptConvergent(hasher hash.Hash, convergenceSecret, context []byte,
+	aeadMaker func([]byte) (cipher.AEAD, error), plaintext,
+	additionalData []byte) (*encryptedBlob, error) {
 
 	// First hash the plaintext securely, we will use its hash as a key
 	hasher.Write(plaintext)
 	secretKey := hasher.Sum(nil)
-	blockCipher, err := blockCipherMaker(secretKey)
+	if len(convergenceSecret) > 0 {
+		mac := hmac.New(sha256.New, convergenceSecret)
+		mac.Write(secretKey)
+		secretKey = mac.Sum(nil)
+	}
+	if len(context) > 0 {
+		mac := hmac.New(sha256.New, secretKey)
+		mac.Write(context)
+		secretKey = mac.Sum(nil)
+	}
+	return encryptOneTime(secretKey, aeadMaker, plaintext, additionalData)
+}
+
+// Encrypt plaintext with a freshly generated random secret key of keySize
+// bytes. This is semantically secure but gives up recovering the secret key
+// from the plaintext and deduplication. A nonce is still unnecessary since the
+// key is never reused, and a random nonce would need to be stored alongside
+// the ciphertext whereas this way it decrypts exactly like any other blob.
+func encryptRandom(keySize int, aeadMaker func([]byte) (cipher.AEAD, error),
+	plaintext, additionalData []byte) (*encryptedBlob, error) {
+
+	secretKey := make([]byte, keySize)
+	_, err := rand.Read(secretKey)
 	if err != nil {
 		return nil, err
 	}
-	// We can operate without a nonce because we are using a one-time key (the
-	// secure hash of the data) that will be not used for other messages (blobs)
-	// so IV/key pair is unique
-	gcmCipher, err := cipher.NewGCMWithNonceSize(blockCipher, 0)
+	return encryptOneTime(secretKey, aeadMaker, plaintext, additionalData)
+}
+
+func encryptOneTime(secretKey []byte, aeadMaker func([]byte) (cipher.AEAD, error),
+	plaintext, additionalData []byte) (*encryptedBlob, error) {
+
+	aead, err := aeadMaker(secretKey)
 	if err != nil {
 		return nil, err
 	}
-
-	// Encrypt authenticated with Galois Counter mode
-	// TODO: consider storing contract address relating to blob in additional data
-	ciphertext := gcmCipher.Seal(nil, nil, plaintext, additionalData)
+	// We can operate with a fixed nonce because we are using a one-time key (the
+	// secure hash of the data or a random key) that will be not used for other
+	// messages (blobs) so IV/key pair is unique
+	ciphertext := aead.Seal(nil, make([]byte, aead.NonceSize()), plaintext,
+		additionalData)
 
 	return &encryptedBlob{
 		secretKey:     secretKey,
@@ -104,24 +269,18 @@ func encryptConvergent(hasher hash.Hash, blockCipherMaker BlockCipherMaker,
 	}, nil
 }
 
-// Decrypt ciphertext encrypted in Galois Counter Mode over the block cipher
-// provided by blockCipherMaker assuming a one-time key and so no nonce as
-// would be encrypted by encryptConvergent (though would work for any one-time
-// key case). Salt is used as GCM additional authenticated data.
-func decryptConvergent(blockCipherMaker BlockCipherMaker, secretKey,
+// Decrypt ciphertext encrypted with the AEAD provided by aeadMaker assuming a
+// one-time key and so a fixed nonce as would be encrypted by encryptConvergent
+// (though would work for any one-time key case). Salt is used as additional
+// authenticated data.
+func decryptConvergent(aeadMaker func([]byte) (cipher.AEAD, error), secretKey,
 	ciphertext, additionalData []byte) ([]byte, error) {
-	// Construct the underlying block cipher
-	blockCipher, err := blockCipherMaker(secretKey)
-	if err != nil {
-		return nil, err
-	}
-
-	gcmCipher, err := cipher.NewGCMWithNonceSize(blockCipher, 0)
+	aead, err := aeadMaker(secretKey)
 	if err != nil {
 		return nil, err
 	}
-
-	return gcmCipher.Open(nil, nil, ciphertext, additionalData)
+	return aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext,
+		additionalData)
 }
 
 func salinate(plaintext, salt []byte) []byte {
@@ -132,19 +291,45 @@ func desalinate(ciphertext, salt []byte) []byte {
 	return ciphertext[len(salt):]
 }
 
-// Provides additional authenticated data to fix context of our salting procedure
-// using this means if we try to decrypt an unsalted message with a salt or visa
-// versa we will get an error decrypting.
-func additionalDataForSalt(salt []byte) []byte {
-	if len(salt) == 0 {
+// Authenticate the ciphertext header (if any) along with the salting and
+// padding procedures and any caller-supplied context
+func additionalData(header, salt []byte, padding *Padding, context []byte) []byte {
+	plaintextData := additionalDataForSaltAndPadding(salt, padding.Name)
+	if len(context) > 0 {
+		plaintextData = append(plaintextData, additionalDataForContext(context)...)
+	}
+	if len(header) == 0 {
+		return plaintextData
+	}
+	return append(append([]byte{}, header...), plaintextData...)
+}
+
+// The context is length-prefixed so that it cannot be confused with the
+// description of the salting and padding procedures preceding it
+func additionalDataForContext(context []byte) []byte {
+	data := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(context))
+	n := binary.PutUvarint(data, uint64(len(context)))
+	return append(data[:n], context...)
+}
+
+// Provides additional authenticated data to fix context of our salting and
+// padding procedures using this means if we try to decrypt an unsalted message
+// with a salt or visa versa (or with the wrong padding) we will get an error
+// decrypting. Unsalted and unpadded messages have no additional data.
+func additionalDataForSaltAndPadding(salt []byte, padding string) []byte {
+	if len(salt) == 0 && padding == NoPadding {
 		return nil
 	}
 	additionalData := struct {
-		SaltType   string
-		SaltLength int
+		SaltType   string `json:",omitempty"`
+		SaltLength int    `json:",omitempty"`
+		Padding    string `json:",omitempty"`
 	}{
-		SaltType:   "prefix",
-		SaltLength: len(salt),
+		Padding: padding,
+	}
+	if len(salt) > 0 {
+		additionalData.SaltType = "prefix"
+		additionalData.SaltLength = len(salt)
 	}
 	jsonBytes, err := json.Marshal(additionalData)
 	if err != nil {
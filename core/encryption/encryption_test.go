@@ -1,6 +1,7 @@
 package encryption
 
 import (
+	"crypto/sha256"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -45,10 +46,88 @@ func TestSaltedEncryptionRoundTrip(t *testing.T) {
 	assert.Error(t, err, "Should fail on unsalted decrypt of salted blob")
 }
 
-func TestAdditionalDataForSalt(t *testing.T) {
+func TestAdditionalDataForSaltAndPadding(t *testing.T) {
 	// This function may panic on marshalling so we try to cover it here
-	assert.Nil(t, additionalDataForSalt(nil))
-	assert.Nil(t, additionalDataForSalt([]byte("")))
+	assert.Nil(t, additionalDataForSaltAndPadding(nil, NoPadding))
+	assert.Nil(t, additionalDataForSaltAndPadding([]byte(""), NoPadding))
 	assert.Equal(t, "{\"SaltType\":\"prefix\",\"SaltLength\":21}",
-		string(additionalDataForSalt([]byte("I _am_ a magical fish"))))
+		string(additionalDataForSaltAndPadding([]byte("I _am_ a magical fish"),
+			NoPadding)))
+	assert.Equal(t, "{\"Padding\":\"padme\"}",
+		string(additionalDataForSaltAndPadding(nil, PadmePadding)))
+	assert.Equal(t, "{\"SaltType\":\"prefix\",\"SaltLength\":4,"+
+		"\"Padding\":\"power-of-two\"}",
+		string(additionalDataForSaltAndPadding([]byte("fish"), PowerOfTwoPadding)))
+}
+
+func TestConvergenceSecret(t *testing.T) {
+	plaintext := []byte("Hello this is a string")
+	unkeyed, err := Encrypt(plaintext, nil)
+	assert.NoError(t, err)
+	keyed, err := EncryptWithOptions(sha256.New, plaintext, nil,
+		Options{ConvergenceSecret: []byte("secret")})
+	assert.NoError(t, err)
+	otherKeyed, err := EncryptWithOptions(sha256.New, plaintext, nil,
+		Options{ConvergenceSecret: []byte("other secret")})
+	assert.NoError(t, err)
+	// Only deduplicated under the same secret
+	assert.NotEqual(t, unkeyed.SecretKey(), keyed.SecretKey())
+	assert.NotEqual(t, keyed.EncryptedData(), otherKeyed.EncryptedData())
+	sameKeyed, err := EncryptWithOptions(sha256.New, plaintext, nil,
+		Options{ConvergenceSecret: []byte("secret")})
+	assert.NoError(t, err)
+	assert.Equal(t, keyed, sameKeyed)
+
+	// The secret is not needed to decrypt
+	decrypted, err := Decrypt(keyed.SecretKey(), keyed.EncryptedData(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestRandomEncryption(t *testing.T) {
+	plaintext := []byte("Hello this is a string")
+	salt := []byte("salty like the sea")
+	blob1, err := EncryptWithOptions(sha256.New, plaintext, salt,
+		Options{Random: true})
+	assert.NoError(t, err)
+	blob2, err := EncryptWithOptions(sha256.New, plaintext, salt,
+		Options{Random: true})
+	assert.NoError(t, err)
+	assert.Len(t, blob1.SecretKey(), sha256.Size)
+	assert.NotEqual(t, blob1.SecretKey(), blob2.SecretKey())
+	assert.NotEqual(t, blob1.EncryptedData(), blob2.EncryptedData())
+
+	for _, blob := range []EncryptedBlob{blob1, blob2} {
+		decrypted, err := Decrypt(blob.SecretKey(), blob.EncryptedData(), salt)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestContext(t *testing.T) {
+	plaintext := []byte("Hello this is a string")
+	context := []byte("tenant-1")
+	blob, err := EncryptWithOptions(sha256.New, plaintext, nil,
+		Options{Context: context})
+	assert.NoError(t, err)
+	decrypted, err := DecryptWithOptions(blob.SecretKey(), blob.EncryptedData(),
+		nil, Options{Context: context})
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Only decrypts with the same context
+	_, err = DecryptWithOptions(blob.SecretKey(), blob.EncryptedData(), nil,
+		Options{Context: []byte("tenant-2")})
+	assert.Error(t, err)
+	_, err = Decrypt(blob.SecretKey(), blob.EncryptedData(), nil)
+	assert.Error(t, err)
+
+	// Blobs differing only in context do not share a key
+	otherBlob, err := EncryptWithOptions(sha256.New, plaintext, nil,
+		Options{Context: []byte("tenant-2")})
+	assert.NoError(t, err)
+	assert.NotEqual(t, blob.SecretKey(), otherBlob.SecretKey())
+	unboundBlob, err := Encrypt(plaintext, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, blob.SecretKey(), unboundBlob.SecretKey())
 }
@@ -0,0 +1,171 @@
+package encryption
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const gcmBlockSize = 16
+const gcmTagSize = 16
+
+// Galois Counter Mode with an empty nonce, which is safe since our keys are
+// only ever used for one message and keeps ciphertexts (and so addresses)
+// identical to those Hoard has always produced. The standard library no longer
+// accepts zero-length nonces so we implement it here following NIST SP
+// 800-38D. With an empty nonce the pre-counter block J0 is GHASH of a single
+// zero block, which is zero, so encryption starts at counter 1 and the tag is
+// masked with the encryption of the zero block.
+type emptyNonceGCM struct {
+	block cipher.Block
+	// The hash subkey H
+	h gcmFieldElement
+}
+
+var _ cipher.AEAD = (*emptyNonceGCM)(nil)
+
+func newEmptyNonceGCM(block cipher.Block) (*emptyNonceGCM, error) {
+	if block.BlockSize() != gcmBlockSize {
+		return nil, errors.New("cipher: empty nonce GCM requires a 128-bit block cipher")
+	}
+	var h [gcmBlockSize]byte
+	block.Encrypt(h[:], h[:])
+	return &emptyNonceGCM{
+		block: block,
+		h:     readFieldElement(h[:]),
+	}, nil
+}
+
+func (g *emptyNonceGCM) NonceSize() int {
+	return 0
+}
+
+func (g *emptyNonceGCM) Overhead() int {
+	return gcmTagSize
+}
+
+func (g *emptyNonceGCM) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != 0 {
+		panic("cipher: empty nonce GCM does not take a nonce")
+	}
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmTagSize)
+	g.counterMode(out, plaintext)
+	g.tag(out[len(plaintext):], additionalData, out[:len(plaintext)])
+	return ret
+}
+
+func (g *emptyNonceGCM) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 0 {
+		return nil, errors.New("cipher: empty nonce GCM does not take a nonce")
+	}
+	if len(ciphertext) < gcmTagSize {
+		return nil, errors.New("cipher: message authentication failed")
+	}
+	tagIndex := len(ciphertext) - gcmTagSize
+	var expectedTag [gcmTagSize]byte
+	g.tag(expectedTag[:], additionalData, ciphertext[:tagIndex])
+	if subtle.ConstantTimeCompare(expectedTag[:], ciphertext[tagIndex:]) != 1 {
+		return nil, errors.New("cipher: message authentication failed")
+	}
+	ret, out := sliceForAppend(dst, tagIndex)
+	g.counterMode(out, ciphertext[:tagIndex])
+	return ret, nil
+}
+
+// XOR in with the keystream starting at counter block 1
+func (g *emptyNonceGCM) counterMode(out, in []byte) {
+	var counter, keystream [gcmBlockSize]byte
+	for i := uint32(1); len(in) > 0; i++ {
+		// Only the low 32 bits are incremented (inc32)
+		binary.BigEndian.PutUint32(counter[12:], i)
+		g.block.Encrypt(keystream[:], counter[:])
+		n := len(in)
+		if n > gcmBlockSize {
+			n = gcmBlockSize
+		}
+		for j := 0; j < n; j++ {
+			out[j] = in[j] ^ keystream[j]
+		}
+		in, out = in[n:], out[n:]
+	}
+}
+
+// Write the authentication tag for ciphertext and additionalData to out
+func (g *emptyNonceGCM) tag(out, additionalData, ciphertext []byte) {
+	var s gcmFieldElement
+	g.ghashUpdate(&s, additionalData)
+	g.ghashUpdate(&s, ciphertext)
+	s.high ^= uint64(len(additionalData)) * 8
+	s.low ^= uint64(len(ciphertext)) * 8
+	s = s.mul(g.h)
+	// The tag mask is the encryption of J0 which is the zero block
+	var mask [gcmBlockSize]byte
+	g.block.Encrypt(mask[:], mask[:])
+	binary.BigEndian.PutUint64(out, s.high)
+	binary.BigEndian.PutUint64(out[8:], s.low)
+	for i := range mask {
+		out[i] ^= mask[i]
+	}
+}
+
+// Absorb data into the GHASH state y, zero-padding the final block
+func (g *emptyNonceGCM) ghashUpdate(y *gcmFieldElement, data []byte) {
+	for len(data) > 0 {
+		var block [gcmBlockSize]byte
+		n := copy(block[:], data)
+		x := readFieldElement(block[:])
+		y.high ^= x.high
+		y.low ^= x.low
+		*y = y.mul(g.h)
+		data = data[n:]
+	}
+}
+
+// An element of GF(2^128) in GCM's bit-reflected representation where the
+// most significant bit of high is the coefficient of x^0
+type gcmFieldElement struct {
+	high, low uint64
+}
+
+func readFieldElement(bs []byte) gcmFieldElement {
+	return gcmFieldElement{
+		high: binary.BigEndian.Uint64(bs),
+		low:  binary.BigEndian.Uint64(bs[8:]),
+	}
+}
+
+// Multiply in GF(2^128) modulo x^128 + x^7 + x^2 + x + 1 (Algorithm 1 of SP
+// 800-38D)
+func (x gcmFieldElement) mul(y gcmFieldElement) gcmFieldElement {
+	var z gcmFieldElement
+	v := y
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = x.high >> uint(63-i) & 1
+		} else {
+			bit = x.low >> uint(127-i) & 1
+		}
+		// Constant-time conditional XOR of v into z
+		mask := -bit
+		z.high ^= v.high & mask
+		z.low ^= v.low & mask
+		reduce := -(v.low & 1)
+		v.low = v.low>>1 | v.high<<63
+		v.high = v.high>>1 ^ 0xe100000000000000&reduce
+	}
+	return z
+}
+
+// Extend in by n bytes returning the whole slice and the extension
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
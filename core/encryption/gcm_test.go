@@ -0,0 +1,38 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyNonceGCM(t *testing.T) {
+	// Ciphertexts must match those produced by the standard library before it
+	// stopped accepting empty nonces so that existing addresses are stable
+	plaintext := []byte("hot buns")
+	secretKey := sha256.Sum256(plaintext)
+	blockCipher, err := aes.NewCipher(secretKey[:])
+	assert.NoError(t, err)
+	gcm, err := newEmptyNonceGCM(blockCipher)
+	assert.NoError(t, err)
+
+	ciphertext := gcm.Seal(nil, nil, plaintext, nil)
+	digest := sha256.Sum256(ciphertext)
+	assert.Equal(t, "2768b87bc22a7f0f20eb268937a00d682bc5dc21a62badf1d24c78633436a1b7",
+		hex.EncodeToString(digest[:]))
+
+	decrypted, err := gcm.Open(nil, nil, ciphertext, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	_, err = gcm.Open(nil, nil, ciphertext, []byte("additional data"))
+	assert.Error(t, err)
+	ciphertext[0] ^= 1
+	_, err = gcm.Open(nil, nil, ciphertext, nil)
+	assert.Error(t, err)
+	_, err = gcm.Open(nil, nil, ciphertext[:gcmTagSize-1], nil)
+	assert.Error(t, err)
+}
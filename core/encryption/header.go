@@ -0,0 +1,79 @@
+package encryption
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// Version of the ciphertext header format
+	HeaderVersion = 1
+	// Length in bytes of an encoded header
+	HeaderLength = len(headerMagic) + 1 + 1 + 4
+	// Segment size recorded for ciphertexts encrypted as a single segment
+	UnsegmentedSegmentSize = 0
+)
+
+const headerMagic = "HRD"
+
+// An optional header prefixed to a ciphertext recording the parameters it was
+// encrypted with so that they can be read without the secret key. The encoded
+// header is included in the additional authenticated data of the cipher so it
+// cannot be altered without failing decryption. Encoded as:
+//
+//	magic ("HRD") | version (1 byte) | suite code (1 byte) | segment size (uint32 big-endian)
+type Header struct {
+	Version uint8
+	Suite   string
+	// The size of the plaintext segments encrypted separately or
+	// UnsegmentedSegmentSize if the plaintext was encrypted as a whole
+	SegmentSize uint32
+}
+
+func NewHeader(suite *Suite) *Header {
+	return &Header{
+		Version:     HeaderVersion,
+		Suite:       suite.Name,
+		SegmentSize: UnsegmentedSegmentSize,
+	}
+}
+
+func (header *Header) Bytes() []byte {
+	suite, err := GetSuite(header.Suite)
+	if err != nil {
+		// We only construct headers from existing suites
+		panic(err)
+	}
+	bs := make([]byte, HeaderLength)
+	copy(bs, headerMagic)
+	bs[len(headerMagic)] = header.Version
+	bs[len(headerMagic)+1] = suite.Code
+	binary.BigEndian.PutUint32(bs[len(headerMagic)+2:], header.SegmentSize)
+	return bs
+}
+
+// Parse the header prefixed to encryptedData returning an error if it does not
+// start with a valid header (as is the case for ciphertexts encrypted without
+// one). Since a ciphertext without a header could by chance begin with a
+// valid header a successfully parsed header is only authoritative once the
+// ciphertext has been decrypted with it.
+func ParseHeader(encryptedData []byte) (*Header, error) {
+	if len(encryptedData) < HeaderLength ||
+		string(encryptedData[:len(headerMagic)]) != headerMagic {
+		return nil, fmt.Errorf("Ciphertext does not start with a Hoard header")
+	}
+	header := &Header{
+		Version:     encryptedData[len(headerMagic)],
+		SegmentSize: binary.BigEndian.Uint32(encryptedData[len(headerMagic)+2:]),
+	}
+	if header.Version != HeaderVersion {
+		return nil, fmt.Errorf("Ciphertext header has version %v but only "+
+			"version %v is supported", header.Version, HeaderVersion)
+	}
+	suite, err := getSuiteByCode(encryptedData[len(headerMagic)+1])
+	if err != nil {
+		return nil, err
+	}
+	header.Suite = suite.Name
+	return header, nil
+}
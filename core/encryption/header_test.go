@@ -0,0 +1,94 @@
+package encryption
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	suite, err := GetSuite(ChaCha20Poly1305Suite)
+	assert.NoError(t, err)
+	header := NewHeader(suite)
+	bs := header.Bytes()
+	assert.Equal(t, []byte{'H', 'R', 'D', 1, 2, 0, 0, 0, 0}, bs)
+	assert.Len(t, bs, HeaderLength)
+	parsed, err := ParseHeader(append(bs, "ciphertext"...))
+	assert.NoError(t, err)
+	assert.Equal(t, header, parsed)
+
+	for _, invalid := range [][]byte{
+		nil,
+		bs[:HeaderLength-1],
+		[]byte("ciphertext without a header"),
+		// Unsupported version
+		{'H', 'R', 'D', 2, 1, 0, 0, 0, 0},
+		// Unknown suite
+		{'H', 'R', 'D', 1, 0xff, 0, 0, 0, 0},
+	} {
+		_, err = ParseHeader(invalid)
+		assert.Error(t, err, "%x", invalid)
+	}
+}
+
+func TestEncryptWithHeader(t *testing.T) {
+	plaintext := []byte("Hello this is a string")
+	salt := []byte("salty like the sea")
+	for _, name := range SuiteNames() {
+		blob, err := EncryptWithOptions(sha256.New, plaintext, salt,
+			Options{Suite: name, Header: true})
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		header, err := ParseHeader(blob.EncryptedData())
+		assert.NoError(t, err, name)
+		assert.Equal(t, name, header.Suite)
+
+		// Decryption dispatches on the header regardless of the suite given
+		decrypted, err := Decrypt(blob.SecretKey(), blob.EncryptedData(), salt)
+		assert.NoError(t, err, name)
+		assert.Equal(t, plaintext, decrypted, name)
+
+		// The header is authenticated
+		tampered := append([]byte{}, blob.EncryptedData()...)
+		tampered[HeaderLength-1] ^= 1
+		_, err = Decrypt(blob.SecretKey(), tampered, salt)
+		assert.Error(t, err, name)
+	}
+
+	// The header is not part of the secret key
+	withHeader, err := EncryptWithOptions(sha256.New, plaintext, nil,
+		Options{Header: true})
+	assert.NoError(t, err)
+	withoutHeader, err := Encrypt(plaintext, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, withoutHeader.SecretKey(), withHeader.SecretKey())
+	assert.NotEqual(t, withoutHeader.EncryptedData(), withHeader.EncryptedData())
+}
+
+func TestHeaderSuiteMismatch(t *testing.T) {
+	plaintext := []byte("Hello this is a string")
+	blob, err := EncryptWithOptions(sha256.New, plaintext, nil,
+		Options{Suite: AES256GCMSuite, Header: true})
+	assert.NoError(t, err)
+
+	// A header cannot stand in for the suite the reference expects
+	_, err = DecryptWithOptions(blob.SecretKey(), blob.EncryptedData(), nil,
+		Options{Suite: AES256GCMCommittingSuite})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "header records suite")
+	}
+	decrypted, err := DecryptWithOptions(blob.SecretKey(), blob.EncryptedData(),
+		nil, Options{Suite: AES256GCMSuite})
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Errors from decrypting with the header are not masked
+	segmented := append([]byte{}, blob.EncryptedData()...)
+	segmented[HeaderLength-1] = 1
+	_, err = Decrypt(blob.SecretKey(), segmented, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "segmented ciphertexts are not supported")
+	}
+}
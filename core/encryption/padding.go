@@ -0,0 +1,121 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// Padmé padding (Nikitin et al., 'Reducing Metadata Leakage from Encrypted
+	// Files and Communication with PURBs') leaks O(log log L) bits of the
+	// length L with at most 12% overhead
+	PadmePadding = "padme"
+	// Pad to the next power of two leaking O(log log L) bits of the length with
+	// up to 100% overhead
+	PowerOfTwoPadding = "power-of-two"
+
+	NoPadding = ""
+)
+
+// The byte marking the end of the plaintext in a padded plaintext, which is
+// followed only by zero bytes (as in ISO/IEC 7816-4)
+const paddingMarker = 0x80
+
+// A length-hiding padding scheme applied to plaintexts before encryption so
+// that ciphertext sizes only reveal which bucket the plaintext size falls in.
+// The name of the scheme is recorded in the reference to the blob and
+// authenticated as additional data.
+type Padding struct {
+	Name string
+	// The size to pad a plaintext of size bytes (including the padding marker)
+	// to, nil for no padding
+	PaddedSize func(size uint64) uint64
+}
+
+var paddings = map[string]*Padding{
+	NoPadding: {
+		Name: NoPadding,
+	},
+	PadmePadding: {
+		Name:       PadmePadding,
+		PaddedSize: padmeSize,
+	},
+	PowerOfTwoPadding: {
+		Name:       PowerOfTwoPadding,
+		PaddedSize: powerOfTwoSize,
+	},
+}
+
+func GetPadding(name string) (*Padding, error) {
+	padding, ok := paddings[name]
+	if !ok {
+		return nil, fmt.Errorf("Padding '%s' is not supported, supported "+
+			"paddings are: %v", name, PaddingNames())
+	}
+	return padding, nil
+}
+
+// The names of the padding schemes excluding NoPadding
+func PaddingNames() []string {
+	names := make([]string, 0, len(paddings))
+	for name := range paddings {
+		if name != NoPadding {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Append the padding marker then zeros up to the padded size
+func (padding *Padding) Pad(plaintext []byte) []byte {
+	if padding.PaddedSize == nil {
+		return plaintext
+	}
+	size := uint64(len(plaintext)) + 1
+	padded := make([]byte, padding.PaddedSize(size))
+	copy(padded, plaintext)
+	padded[len(plaintext)] = paddingMarker
+	return padded
+}
+
+// Remove the padding added by Pad checking it is exactly as Pad would produce
+func (padding *Padding) Unpad(padded []byte) ([]byte, error) {
+	if padding.PaddedSize == nil {
+		return padded, nil
+	}
+	i := len(padded) - 1
+	for i >= 0 && padded[i] == 0 {
+		i--
+	}
+	if i < 0 || padded[i] != paddingMarker {
+		return nil, errors.New("Padded plaintext has no padding marker")
+	}
+	if padding.PaddedSize(uint64(i)+1) != uint64(len(padded)) {
+		return nil, fmt.Errorf("Padded plaintext has %v bytes but %s padding "+
+			"of %v bytes would have %v", len(padded), padding.Name, i,
+			padding.PaddedSize(uint64(i)+1))
+	}
+	return padded[:i], nil
+}
+
+func padmeSize(size uint64) uint64 {
+	if size < 2 {
+		return size
+	}
+	// size = 2^exponent + ... so keep the top bitLength(exponent) + 1 bits of
+	// size and round up the rest
+	exponent := uint64(bits.Len64(size) - 1)
+	lowBits := exponent - uint64(bits.Len64(exponent))
+	mask := uint64(1)<<lowBits - 1
+	return (size + mask) &^ mask
+}
+
+func powerOfTwoSize(size uint64) uint64 {
+	if size < 2 {
+		return size
+	}
+	return 1 << uint(bits.Len64(size-1))
+}
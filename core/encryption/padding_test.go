@@ -0,0 +1,92 @@
+package encryption
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaddedSizes(t *testing.T) {
+	for size, expected := range map[uint64]uint64{
+		0: 0, 1: 1, 2: 2, 7: 7, 8: 8, 9: 10, 33: 36, 100: 104, 1000: 1024,
+		1025: 1088, 1 << 20: 1 << 20, 1<<20 + 1: 1<<20 + 1<<15,
+	} {
+		assert.Equal(t, expected, padmeSize(size), "padme(%v)", size)
+	}
+	for size, expected := range map[uint64]uint64{
+		0: 0, 1: 1, 2: 2, 3: 4, 9: 16, 1000: 1024, 1024: 1024, 1025: 2048,
+	} {
+		assert.Equal(t, expected, powerOfTwoSize(size), "powerOfTwo(%v)", size)
+	}
+}
+
+func TestPadUnpad(t *testing.T) {
+	for _, name := range append(PaddingNames(), NoPadding) {
+		padding, err := GetPadding(name)
+		assert.NoError(t, err)
+		for size := 0; size < 300; size++ {
+			plaintext := make([]byte, size)
+			for i := range plaintext {
+				// Include trailing zeros and markers in the plaintext
+				plaintext[i] = byte(i % 3 * paddingMarker / 2)
+			}
+			padded := padding.Pad(plaintext)
+			if padding.PaddedSize != nil {
+				assert.Equal(t, padding.PaddedSize(uint64(size)+1),
+					uint64(len(padded)))
+			}
+			unpadded, err := padding.Unpad(padded)
+			assert.NoError(t, err, "%s %v", name, size)
+			assert.Equal(t, plaintext, unpadded, "%s %v", name, size)
+		}
+	}
+	_, err := GetPadding("rot13")
+	assert.Error(t, err)
+
+	padme, err := GetPadding(PadmePadding)
+	assert.NoError(t, err)
+	for _, invalid := range [][]byte{
+		nil,
+		{0, 0, 0, 0},
+		// Padded too far
+		{1, paddingMarker, 0, 0, 0, 0, 0, 0, 0, 0},
+		// Not padded far enough
+		{1, 2, 3, 4, 5, 6, 7, 8, paddingMarker},
+	} {
+		_, err = padme.Unpad(invalid)
+		assert.Error(t, err, "%x", invalid)
+	}
+}
+
+func TestPaddedEncryption(t *testing.T) {
+	salt := []byte("salt")
+	for _, data := range []string{"Hello this is a string", "Hello this is a string!"} {
+		blob, err := EncryptWithOptions(sha256.New, []byte(data), salt,
+			Options{Padding: PowerOfTwoPadding})
+		assert.NoError(t, err)
+		// Salt, data, and marker padded to 32 bytes then the GCM tag
+		assert.Len(t, blob.EncryptedData(), 32+16)
+
+		decrypted, err := DecryptWithOptions(blob.SecretKey(), blob.EncryptedData(),
+			salt, Options{Padding: PowerOfTwoPadding})
+		assert.NoError(t, err)
+		assert.Equal(t, data, string(decrypted))
+
+		// The padding scheme is authenticated
+		_, err = DecryptWithOptions(blob.SecretKey(), blob.EncryptedData(),
+			salt, Options{Padding: PadmePadding})
+		assert.Error(t, err)
+		_, err = DecryptWithOptions(blob.SecretKey(), blob.EncryptedData(),
+			salt, Options{})
+		assert.Error(t, err)
+	}
+
+	// Padded and unpadded plaintexts have different secret keys
+	padded, err := EncryptWithOptions(sha256.New, []byte("data"), nil,
+		Options{Padding: PadmePadding})
+	assert.NoError(t, err)
+	unpadded, err := Encrypt([]byte("data"), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, unpadded.SecretKey(), padded.SecretKey())
+}
@@ -0,0 +1,190 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// AES-256 in Galois Counter Mode, the cipher Hoard has always used
+	AES256GCMSuite = "aes-256-gcm"
+	// ChaCha20-Poly1305 (RFC 8439), faster than AES on hosts without AES-NI
+	ChaCha20Poly1305Suite = "chacha20-poly1305"
+	// AES-256-GCM with a key commitment so that a ciphertext can only decrypt
+	// validly under a single secret key
+	AES256GCMCommittingSuite = "aes-256-gcm-committing"
+
+	DefaultSuite = AES256GCMSuite
+)
+
+// An authenticated encryption scheme that can be selected to encrypt a blob.
+// The name of the suite is recorded in the reference to the blob so that it can
+// be decrypted with the same suite.
+type Suite struct {
+	Name string
+	// Identifies the suite in ciphertext headers
+	Code byte
+	// Construct the AEAD for a one-time secret key
+	NewAEAD func(secretKey []byte) (cipher.AEAD, error)
+	// The commitment to the secret key prefixed to ciphertexts by suites that
+	// commit to their key, nil for suites that do not
+	KeyCommitment func(secretKey []byte) []byte
+}
+
+var suites = map[string]*Suite{
+	AES256GCMSuite: {
+		Name:    AES256GCMSuite,
+		Code:    1,
+		NewAEAD: newAESGCM,
+	},
+	ChaCha20Poly1305Suite: {
+		Name:    ChaCha20Poly1305Suite,
+		Code:    2,
+		NewAEAD: chacha20poly1305.New,
+	},
+	AES256GCMCommittingSuite: {
+		Name:          AES256GCMCommittingSuite,
+		Code:          3,
+		NewAEAD:       newCommittingAESGCM,
+		KeyCommitment: keyCommitment,
+	},
+}
+
+// Get the suite with name, where the empty name gives the default suite (so
+// references recorded before suites were introduced decrypt as AES-256-GCM)
+func GetSuite(name string) (*Suite, error) {
+	if name == "" {
+		name = DefaultSuite
+	}
+	suite, ok := suites[name]
+	if !ok {
+		return nil, fmt.Errorf("Cipher suite '%s' is not supported, supported "+
+			"suites are: %v", name, SuiteNames())
+	}
+	return suite, nil
+}
+
+func getSuiteByCode(code byte) (*Suite, error) {
+	for _, suite := range suites {
+		if suite.Code == code {
+			return suite, nil
+		}
+	}
+	return nil, fmt.Errorf("Cipher suite with code %v is not supported", code)
+}
+
+func SuiteNames() []string {
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newAESGCM(secretKey []byte) (cipher.AEAD, error) {
+	blockCipher, err := aes.NewCipher(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return newEmptyNonceGCM(blockCipher)
+}
+
+// Key-committing AEAD following the 'CommitKey' construction of Bellare and
+// Hoang: the encryption key and a commitment to the secret key are derived
+// from the secret key with independent PRF calls and the commitment prefixed to
+// the ciphertext. Since HMAC-SHA256 is collision resistant no two secret keys
+// yield the same commitment, so a ciphertext cannot be opened under two keys.
+type committingAEAD struct {
+	aead       cipher.AEAD
+	commitment []byte
+}
+
+const commitmentSize = sha256.Size
+
+var _ cipher.AEAD = (*committingAEAD)(nil)
+
+func newCommittingAESGCM(secretKey []byte) (cipher.AEAD, error) {
+	aead, err := newAESGCM(deriveKey(secretKey, "hoard-encryption-key"))
+	if err != nil {
+		return nil, err
+	}
+	return &committingAEAD{
+		aead:       aead,
+		commitment: keyCommitment(secretKey),
+	}, nil
+}
+
+func keyCommitment(secretKey []byte) []byte {
+	return deriveKey(secretKey, "hoard-key-commitment")
+}
+
+// Check that encryptedData (with or without a header) begins with commitment
+// and is long enough to have been encrypted by a key-committing suite. This
+// needs only the commitment, not the secret key, so it cannot check the
+// authentication tag or that the ciphertext decrypts.
+func VerifyKeyCommitment(commitment, encryptedData []byte) error {
+	if len(commitment) != commitmentSize {
+		return fmt.Errorf("Key commitment should be %v bytes but is %v",
+			commitmentSize, len(commitment))
+	}
+	ciphertext := encryptedData
+	header, err := ParseHeader(encryptedData)
+	if err == nil && hasPrefix(encryptedData[HeaderLength:], commitment) {
+		ciphertext = encryptedData[HeaderLength:]
+		suite, _ := GetSuite(header.Suite)
+		if suite.KeyCommitment == nil {
+			return fmt.Errorf("Ciphertext header records suite '%s' which "+
+				"does not commit to its key", header.Suite)
+		}
+	}
+	if !hasPrefix(ciphertext, commitment) {
+		return errors.New("Ciphertext does not carry the key commitment")
+	}
+	// Confirm there is room for an authentication tag after the commitment
+	if len(ciphertext) < commitmentSize+gcmTagSize {
+		return errors.New("Ciphertext is too short to have been encrypted " +
+			"with a key-committing suite")
+	}
+	return nil
+}
+
+func hasPrefix(data, prefix []byte) bool {
+	return len(data) >= len(prefix) &&
+		subtle.ConstantTimeCompare(data[:len(prefix)], prefix) == 1
+}
+
+func (ca *committingAEAD) NonceSize() int {
+	return ca.aead.NonceSize()
+}
+
+func (ca *committingAEAD) Overhead() int {
+	return commitmentSize + ca.aead.Overhead()
+}
+
+func (ca *committingAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	dst = append(dst, ca.commitment...)
+	return ca.aead.Seal(dst, nonce, plaintext, additionalData)
+}
+
+func (ca *committingAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < commitmentSize ||
+		subtle.ConstantTimeCompare(ca.commitment, ciphertext[:commitmentSize]) != 1 {
+		return nil, errors.New("cipher: key commitment does not match")
+	}
+	return ca.aead.Open(dst, nonce, ciphertext[commitmentSize:], additionalData)
+}
+
+func deriveKey(secretKey []byte, label string) []byte {
+	mac := hmac.New(sha256.New, secretKey)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
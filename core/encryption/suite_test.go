@@ -0,0 +1,104 @@
+package encryption
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuitesRoundTrip(t *testing.T) {
+	plaintext := []byte("Hello this is a string")
+	salt := []byte("salty like the sea")
+	for _, name := range SuiteNames() {
+		blob, err := EncryptWithOptions(sha256.New, plaintext, salt,
+			Options{Suite: name})
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, name, blob.Suite())
+			decrypted, err := DecryptWithOptions(blob.SecretKey(),
+				blob.EncryptedData(), salt, Options{Suite: name})
+			assert.NoError(t, err, name)
+			assert.Equal(t, plaintext, decrypted, name)
+		}
+	}
+
+	_, err := GetSuite("rot13")
+	assert.Error(t, err)
+	suite, err := GetSuite("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSuite, suite.Name)
+}
+
+func TestSuitesDistinct(t *testing.T) {
+	plaintext := []byte("Hello this is a string")
+	ciphertexts := make(map[string]string)
+	for _, name := range SuiteNames() {
+		blob, err := EncryptWithOptions(sha256.New, plaintext, nil,
+			Options{Suite: name})
+		assert.NoError(t, err)
+		for otherName, otherCiphertext := range ciphertexts {
+			assert.NotEqual(t, otherCiphertext, string(blob.EncryptedData()),
+				"%s and %s", name, otherName)
+		}
+		ciphertexts[name] = string(blob.EncryptedData())
+		// Decrypting with the wrong suite should fail
+		for _, otherName := range SuiteNames() {
+			if otherName != name {
+				_, err = DecryptWithOptions(blob.SecretKey(),
+					blob.EncryptedData(), nil, Options{Suite: otherName})
+				assert.Error(t, err, "%s decrypted as %s", name, otherName)
+			}
+		}
+	}
+}
+
+func TestCommittingSuite(t *testing.T) {
+	suite, err := GetSuite(AES256GCMCommittingSuite)
+	assert.NoError(t, err)
+	blob, err := EncryptWithOptions(sha256.New, []byte("committed"), nil,
+		Options{Suite: AES256GCMCommittingSuite})
+	assert.NoError(t, err)
+	// Commitment plus GCM tag
+	assert.Len(t, blob.EncryptedData(), len("committed")+48)
+	committing := Options{Suite: AES256GCMCommittingSuite}
+
+	otherKey := sha256.Sum256([]byte("other key"))
+	_, err = DecryptWithOptions(otherKey[:], blob.EncryptedData(), nil, committing)
+	assert.Error(t, err)
+	// Splicing another key's commitment onto the ciphertext should not help
+	otherAEAD, err := suite.NewAEAD(otherKey[:])
+	assert.NoError(t, err)
+	spliced := append(otherAEAD.(*committingAEAD).commitment,
+		blob.EncryptedData()[commitmentSize:]...)
+	_, err = DecryptWithOptions(otherKey[:], spliced, nil, committing)
+	assert.Error(t, err)
+	_, err = DecryptWithOptions(blob.SecretKey(), spliced, nil, committing)
+	assert.Error(t, err)
+	_, err = DecryptWithOptions(blob.SecretKey(), blob.EncryptedData()[:10],
+		nil, committing)
+	assert.Error(t, err)
+}
+
+func TestVerifyKeyCommitment(t *testing.T) {
+	suite, err := GetSuite(AES256GCMCommittingSuite)
+	assert.NoError(t, err)
+	for _, header := range []bool{false, true} {
+		blob, err := EncryptWithOptions(sha256.New, []byte("committed"), nil,
+			Options{Suite: AES256GCMCommittingSuite, Header: header})
+		assert.NoError(t, err)
+		commitment := suite.KeyCommitment(blob.SecretKey())
+		assert.NoError(t, VerifyKeyCommitment(commitment, blob.EncryptedData()))
+
+		otherKey := sha256.Sum256([]byte("other key"))
+		assert.Error(t, VerifyKeyCommitment(suite.KeyCommitment(otherKey[:]),
+			blob.EncryptedData()))
+		assert.Error(t, VerifyKeyCommitment(commitment[:10], blob.EncryptedData()))
+	}
+
+	// Suites that do not commit to their key cannot be verified
+	blob, err := EncryptWithOptions(sha256.New, []byte("committed"), nil,
+		Options{Header: true})
+	assert.NoError(t, err)
+	assert.Error(t, VerifyKeyCommitment(suite.KeyCommitment(blob.SecretKey()),
+		blob.EncryptedData()))
+}
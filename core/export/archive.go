@@ -0,0 +1,203 @@
+package export
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/monax/hoard/core/reference"
+	"github.com/monax/hoard/core/storage"
+)
+
+const (
+	// Version of the archive index format
+	ArchiveVersion = 1
+	// The name of the index written as the last entry of an archive
+	ArchiveIndexName = "index.json"
+	// The directory within an archive holding blobs named by hex address
+	ArchiveBlobDirectory = "blobs"
+)
+
+// Archives are tar files containing a blobs/<hex address> entry for each
+// ciphertext followed by an index.json entry describing them (written last so
+// archives can be streamed)
+type ArchiveIndex struct {
+	Version int
+	Blobs   []*ArchiveEntry
+	// References to the archived blobs, only included if secret keys were
+	// explicitly requested on export
+	Refs []*reference.Ref `json:",omitempty"`
+}
+
+type ArchiveEntry struct {
+	Address []byte
+	Size    uint64
+	// Hex-encoded SHA256 of the blob
+	SHA256 string
+}
+
+// Write an archive to w holding the ciphertext of each of refs read from
+// store. The secret keys of refs are only written to the archive if
+// includeSecretKeys is true, otherwise only their addresses are recorded.
+func ExportArchive(w io.Writer, store storage.ReadStore, refs []*reference.Ref,
+	includeSecretKeys bool) (*ArchiveIndex, error) {
+
+	tw := tar.NewWriter(w)
+	index := &ArchiveIndex{Version: ArchiveVersion}
+	archived := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		if includeSecretKeys {
+			index.Refs = append(index.Refs, ref)
+		}
+		if archived[string(ref.Address)] {
+			continue
+		}
+		data, err := store.Get(ref.Address)
+		if err != nil {
+			return nil, err
+		}
+		err = writeTarEntry(tw, archiveBlobName(ref.Address), data)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256(data)
+		index.Blobs = append(index.Blobs, &ArchiveEntry{
+			Address: ref.Address,
+			Size:    uint64(len(data)),
+			SHA256:  hex.EncodeToString(digest[:]),
+		})
+		archived[string(ref.Address)] = true
+	}
+	bs, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = writeTarEntry(tw, ArchiveIndexName, bs)
+	if err != nil {
+		return nil, err
+	}
+	return index, tw.Close()
+}
+
+// Read an archive written by ExportArchive from r and put each blob in it into
+// store. Since the index is the last entry the whole archive is read into
+// memory and each blob verified against its address and the index before any
+// blob is put, so a truncated or corrupt archive imports nothing.
+func ImportArchive(r io.Reader, store storage.WriteStore) (*ArchiveIndex, error) {
+	tr := tar.NewReader(r)
+	blobs := make(map[string][]byte)
+	var index *ArchiveIndex
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Could not read archive: %s", err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read archive entry '%s': %s",
+				header.Name, err)
+		}
+		if header.Name == ArchiveIndexName {
+			index = new(ArchiveIndex)
+			err = json.Unmarshal(data, index)
+			if err != nil {
+				return nil, fmt.Errorf("Could not parse archive index: %s", err)
+			}
+			continue
+		}
+		address, err := archiveBlobAddress(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !storage.MatchesAddress(address, data) {
+			return nil, fmt.Errorf("Archived blob '%s' does not match its "+
+				"address", header.Name)
+		}
+		blobs[string(address)] = data
+	}
+	err := checkArchive(index, blobs)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range index.Blobs {
+		err = store.Put(entry.Address, blobs[string(entry.Address)])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// Check the blobs read from an archive are exactly those listed in its index
+func checkArchive(index *ArchiveIndex, blobs map[string][]byte) error {
+	if index == nil {
+		return fmt.Errorf("Archive has no %s so is incomplete or is not a "+
+			"Hoard archive", ArchiveIndexName)
+	}
+	if index.Version != ArchiveVersion {
+		return fmt.Errorf("Archive has version %v but only version %v is "+
+			"supported", index.Version, ArchiveVersion)
+	}
+	listed := make(map[string]bool, len(index.Blobs))
+	for _, entry := range index.Blobs {
+		data, ok := blobs[string(entry.Address)]
+		if !ok {
+			return fmt.Errorf("Blob %s listed in archive index is missing "+
+				"from archive", hex.EncodeToString(entry.Address))
+		}
+		digest := sha256.Sum256(data)
+		if hex.EncodeToString(digest[:]) != entry.SHA256 {
+			return fmt.Errorf("Blob %s does not match SHA256 in archive "+
+				"index", hex.EncodeToString(entry.Address))
+		}
+		listed[string(entry.Address)] = true
+	}
+	for address := range blobs {
+		if !listed[address] {
+			return fmt.Errorf("Blob %s in archive is not listed in archive "+
+				"index", hex.EncodeToString([]byte(address)))
+		}
+	}
+	return nil
+}
+
+func archiveBlobName(address []byte) string {
+	return path.Join(ArchiveBlobDirectory, hex.EncodeToString(address))
+}
+
+func archiveBlobAddress(name string) ([]byte, error) {
+	if path.Dir(name) != ArchiveBlobDirectory {
+		return nil, fmt.Errorf("Unexpected entry '%s' in archive", name)
+	}
+	address, err := hex.DecodeString(strings.TrimPrefix(name,
+		ArchiveBlobDirectory+"/"))
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode archive entry '%s' as a hex "+
+			"address: %s", name, err)
+	}
+	return address, nil
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"github.com/monax/hoard/core"
+	"github.com/monax/hoard/core/encryption"
+	"github.com/monax/hoard/core/reference"
+	"github.com/monax/hoard/core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	store := storage.NewMemoryStore()
+	addressFoo := storage.SHA256Addresser(bs("foo-ciphertext"))
+	addressBar := storage.SHA256Addresser(bs("bar-ciphertext"))
+	assert.NoError(t, store.Put(addressFoo, bs("foo-ciphertext")))
+	assert.NoError(t, store.Put(addressBar, bs("bar-ciphertext")))
+	refs := []*reference.Ref{
+		reference.New(addressFoo, bs("foo-secret-key"), nil),
+		reference.New(addressBar, bs("bar-secret-key"), nil),
+		// Duplicate blobs are only archived once
+		reference.New(addressFoo, bs("foo-secret-key"), bs("salt")),
+	}
+
+	buf := new(bytes.Buffer)
+	index, err := ExportArchive(buf, store, refs, false)
+	assert.NoError(t, err)
+	assert.Len(t, index.Blobs, 2)
+	assert.Empty(t, index.Refs)
+	assert.False(t, bytes.Contains(buf.Bytes(), bs("secret-key")))
+	assert.False(t, bytes.Contains(buf.Bytes(), bs("c2VjcmV0LWtle")))
+
+	target := storage.NewMemoryStore()
+	imported, err := ImportArchive(bytes.NewReader(buf.Bytes()), target)
+	assert.NoError(t, err)
+	assert.Equal(t, index.Blobs, imported.Blobs)
+	data, err := target.Get(addressBar)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("bar-ciphertext"), data)
+
+	// Secret keys when explicitly requested
+	buf.Reset()
+	_, err = ExportArchive(buf, store, refs, true)
+	assert.NoError(t, err)
+	imported, err = ImportArchive(buf, storage.NewMemoryStore())
+	assert.NoError(t, err)
+	assert.Equal(t, refs, imported.Refs)
+}
+
+func TestArchiveRoundTripDecrypts(t *testing.T) {
+	store := storage.NewMemoryStore()
+	hrd := core.NewHoard(store, nil, nil, nil)
+	plaintext := bs("contract state")
+	ref, err := hrd.Put(plaintext, bs("salt"), encryption.Options{
+		Suite:   encryption.ChaCha20Poly1305Suite,
+		Padding: encryption.PadmePadding,
+		Context: bs("contract address"),
+	})
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	_, err = ExportArchive(buf, store, []*reference.Ref{ref}, true)
+	assert.NoError(t, err)
+	target := storage.NewMemoryStore()
+	imported, err := ImportArchive(buf, target)
+	assert.NoError(t, err)
+
+	// The imported reference should decrypt the imported blob
+	if assert.Len(t, imported.Refs, 1) {
+		data, err := core.NewHoard(target, nil, nil, nil).Get(imported.Refs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, data)
+	}
+}
+
+func TestImportArchiveCorrupt(t *testing.T) {
+	store := storage.NewMemoryStore()
+	address := storage.SHA256Addresser(bs("ciphertext"))
+	assert.NoError(t, store.Put(address, bs("ciphertext")))
+	buf := new(bytes.Buffer)
+	_, err := ExportArchive(buf, store,
+		[]*reference.Ref{reference.New(address, nil, nil)}, false)
+	assert.NoError(t, err)
+
+	corrupted := bytes.Replace(buf.Bytes(), bs("ciphertext"), bs("c1phertext"), 1)
+	target := storage.NewMemoryStore()
+	_, err = ImportArchive(bytes.NewReader(corrupted), target)
+	assert.Error(t, err)
+	statInfo, err := target.Stat(address)
+	assert.NoError(t, err)
+	assert.False(t, statInfo.Exists)
+
+	// Truncated before the index, nothing is imported
+	target = storage.NewMemoryStore()
+	_, err = ImportArchive(bytes.NewReader(buf.Bytes()[:1024]), target)
+	assert.Error(t, err)
+	statInfo, err = target.Stat(address)
+	assert.NoError(t, err)
+	assert.False(t, statInfo.Exists)
+
+	// Unsupported index version
+	buf.Reset()
+	tw := tar.NewWriter(buf)
+	assert.NoError(t, writeTarEntry(tw, archiveBlobName(address), bs("ciphertext")))
+	assert.NoError(t, writeTarEntry(tw, ArchiveIndexName,
+		bs(`{"Version": 2, "Blobs": []}`)))
+	assert.NoError(t, tw.Close())
+	_, err = ImportArchive(buf, storage.NewMemoryStore())
+	assert.Error(t, err)
+
+	// Blob not listed in the index
+	buf.Reset()
+	tw = tar.NewWriter(buf)
+	assert.NoError(t, writeTarEntry(tw, archiveBlobName(address), bs("ciphertext")))
+	assert.NoError(t, writeTarEntry(tw, ArchiveIndexName,
+		bs(`{"Version": 1, "Blobs": []}`)))
+	assert.NoError(t, tw.Close())
+	_, err = ImportArchive(buf, storage.NewMemoryStore())
+	assert.Error(t, err)
+}
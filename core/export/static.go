@@ -0,0 +1,313 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/monax/hoard/core/storage"
+)
+
+const (
+	// The name of the index manifest written at the root of a static export
+	ManifestFileName = "index.json"
+	// The extension of the checksum file written alongside each blob
+	ChecksumExtension = ".sha256"
+)
+
+// Describes how exported blobs are spread over nested subdirectories so that
+// no single directory has to hold every blob. Each level of nesting is named
+// by the next Width characters of the encoded address, so with Depth 2 and
+// Width 2 the blob with encoded address 'abcdef' is written to 'ab/cd/abcdef'.
+// The zero value writes every blob into the root directory.
+type Sharding struct {
+	Depth int
+	Width int
+}
+
+type Manifest struct {
+	// Name of the address encoding used to name blob files
+	AddressEncoding string
+	Sharding        Sharding
+	Blobs           []*ManifestEntry
+}
+
+type ManifestEntry struct {
+	Address []byte
+	// Path to blob relative to the root of the export using '/' as separator
+	Path string
+	Size uint64
+	// Hex-encoded SHA256 of the blob if checksums were requested by this or an
+	// earlier export
+	SHA256 string `json:",omitempty"`
+}
+
+type StaticReport struct {
+	Exported [][]byte
+	Skipped  [][]byte
+}
+
+// Writes ciphertexts from a store into a directory tree that can be served
+// as-is by any web server or synced to any object store
+type StaticExporter struct {
+	rootDirectory       string
+	addressEncodingName string
+	addressEncoding     storage.AddressEncoding
+	sharding            Sharding
+	checksums           bool
+}
+
+func NewStaticExporter(rootDirectory, addressEncodingName string,
+	sharding Sharding, checksums bool) (*StaticExporter, error) {
+
+	if sharding.Depth < 0 || sharding.Width < 0 {
+		return nil, fmt.Errorf("Sharding depth and width must be non-negative "+
+			"but got %#v", sharding)
+	}
+	if sharding.Depth > 0 && sharding.Width == 0 {
+		return nil, fmt.Errorf("Sharding width must be positive if depth is "+
+			"positive but got %#v", sharding)
+	}
+	addressEncoding, err := storage.GetAddressEncoding(addressEncodingName)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(rootDirectory, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticExporter{
+		rootDirectory:       rootDirectory,
+		addressEncodingName: addressEncodingName,
+		addressEncoding:     addressEncoding,
+		sharding:            sharding,
+		checksums:           checksums,
+	}, nil
+}
+
+// Export the blobs stored at addresses in store. Blobs that have already been
+// exported are skipped without reading them from store. The index manifest is
+// merged with any existing manifest so repeated exports to the same directory
+// accumulate.
+func (se *StaticExporter) Export(store storage.ReadStore,
+	addresses [][]byte) (*StaticReport, error) {
+
+	manifest, err := se.ReadManifest()
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]*ManifestEntry, len(manifest.Blobs))
+	for _, entry := range manifest.Blobs {
+		entries[string(entry.Address)] = entry
+	}
+
+	report := new(StaticReport)
+	for _, address := range addresses {
+		relativePath, err := se.RelativePath(address)
+		if err != nil {
+			return report, err
+		}
+		entry, exported, err := se.exportBlob(store, address, relativePath)
+		if err != nil {
+			return report, err
+		}
+		entries[string(address)] = entry
+		if exported {
+			report.Exported = append(report.Exported, address)
+		} else {
+			report.Skipped = append(report.Skipped, address)
+		}
+	}
+
+	manifest = &Manifest{
+		AddressEncoding: se.addressEncodingName,
+		Sharding:        se.sharding,
+		Blobs:           make([]*ManifestEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		manifest.Blobs = append(manifest.Blobs, entry)
+	}
+	sort.Slice(manifest.Blobs, func(i, j int) bool {
+		return manifest.Blobs[i].Path < manifest.Blobs[j].Path
+	})
+	return report, se.writeManifest(manifest)
+}
+
+// Read the manifest from the export directory, returning an empty manifest if
+// none has been written yet
+func (se *StaticExporter) ReadManifest() (*Manifest, error) {
+	bs, err := ioutil.ReadFile(se.path(ManifestFileName))
+	if os.IsNotExist(err) {
+		return &Manifest{
+			AddressEncoding: se.addressEncodingName,
+			Sharding:        se.sharding,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(Manifest)
+	err = json.Unmarshal(bs, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read manifest: %s", err)
+	}
+	if manifest.AddressEncoding != se.addressEncodingName ||
+		manifest.Sharding != se.sharding {
+		return nil, fmt.Errorf("Existing export uses address encoding '%s' "+
+			"with sharding %#v so cannot export with address encoding '%s' and "+
+			"sharding %#v", manifest.AddressEncoding, manifest.Sharding,
+			se.addressEncodingName, se.sharding)
+	}
+	return manifest, nil
+}
+
+// Get the path to a blob relative to the root of the export
+func (se *StaticExporter) RelativePath(address []byte) (string, error) {
+	encodedAddress := se.addressEncoding.EncodeToString(address)
+	if strings.ContainsAny(encodedAddress, "/\\") {
+		return "", fmt.Errorf("Encoded address '%s' contains a path separator, "+
+			"use a filesystem safe address encoding", encodedAddress)
+	}
+	if len(encodedAddress) < se.sharding.Depth*se.sharding.Width {
+		return "", fmt.Errorf("Encoded address '%s' is too short for sharding %#v",
+			encodedAddress, se.sharding)
+	}
+	elements := make([]string, 0, se.sharding.Depth+1)
+	for i := 0; i < se.sharding.Depth; i++ {
+		elements = append(elements,
+			encodedAddress[i*se.sharding.Width:(i+1)*se.sharding.Width])
+	}
+	return path.Join(append(elements, encodedAddress)...), nil
+}
+
+func (se *StaticExporter) exportBlob(store storage.ReadStore, address []byte,
+	relativePath string) (*ManifestEntry, bool, error) {
+
+	entry := &ManifestEntry{
+		Address: address,
+		Path:    relativePath,
+	}
+	blobPath := se.path(relativePath)
+	fileInfo, err := os.Stat(blobPath)
+	if err == nil {
+		// Already exported, but fill in any checksum missing from earlier runs
+		// or keep the checksum an earlier run wrote
+		entry.Size = uint64(fileInfo.Size())
+		if se.checksums {
+			data, err := ioutil.ReadFile(blobPath)
+			if err != nil {
+				return nil, false, err
+			}
+			entry.SHA256, err = se.writeChecksum(blobPath, data)
+		} else {
+			entry.SHA256, err = readChecksum(blobPath)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return entry, false, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, false, err
+	}
+
+	data, err := store.Get(address)
+	if err != nil {
+		return nil, false, err
+	}
+	err = writeFileAtomic(blobPath, data)
+	if err != nil {
+		return nil, false, err
+	}
+	entry.Size = uint64(len(data))
+	if se.checksums {
+		entry.SHA256, err = se.writeChecksum(blobPath, data)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return entry, true, nil
+}
+
+// Writes a checksum file in the format understood by sha256sum
+func (se *StaticExporter) writeChecksum(blobPath string, data []byte) (string, error) {
+	digest := sha256.Sum256(data)
+	checksum := hex.EncodeToString(digest[:])
+	line := fmt.Sprintf("%s  %s\n", checksum, filepath.Base(blobPath))
+	checksumPath := blobPath + ChecksumExtension
+	existing, err := ioutil.ReadFile(checksumPath)
+	if err == nil && string(existing) == line {
+		return checksum, nil
+	}
+	return checksum, writeFileAtomic(checksumPath, ([]byte)(line))
+}
+
+// Reads the checksum from a checksum file written by writeChecksum, returning
+// an empty checksum if there is no checksum file
+func readChecksum(blobPath string) (string, error) {
+	checksumPath := blobPath + ChecksumExtension
+	bs, err := ioutil.ReadFile(checksumPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(bs))
+	if len(fields) != 2 || fields[1] != filepath.Base(blobPath) {
+		return "", fmt.Errorf("Checksum file %s is not in the format written "+
+			"by sha256sum", checksumPath)
+	}
+	digest, err := hex.DecodeString(fields[0])
+	if err != nil || len(digest) != sha256.Size {
+		return "", fmt.Errorf("Checksum file %s does not contain a hex-encoded "+
+			"SHA256", checksumPath)
+	}
+	return fields[0], nil
+}
+
+func (se *StaticExporter) writeManifest(manifest *Manifest) error {
+	bs, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(se.path(ManifestFileName), bs)
+}
+
+func (se *StaticExporter) path(relativePath string) string {
+	return filepath.Join(se.rootDirectory, filepath.FromSlash(relativePath))
+}
+
+// Write via a temporary file so that an interrupted export never leaves a
+// partially written file where a complete one is expected
+func writeFileAtomic(filename string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(filename), ".hoard-export-")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/monax/hoard/core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticExporter(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "static_export_test")
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			panic(err)
+		}
+	}()
+	assert.NoError(t, err)
+
+	store := storage.NewMemoryStore()
+	addressFoo := bs("address-foo")
+	addressBar := bs("address-bar")
+	assert.NoError(t, store.Put(addressFoo, bs("foo-data")))
+	assert.NoError(t, store.Put(addressBar, bs("bar-data")))
+
+	se, err := NewStaticExporter(tempDir, storage.HexEncodingName,
+		Sharding{Depth: 2, Width: 2}, true)
+	assert.NoError(t, err)
+
+	relativePath, err := se.RelativePath(addressFoo)
+	assert.NoError(t, err)
+	assert.Equal(t, "61/64/616464726573732d666f6f", relativePath)
+
+	report, err := se.Export(store, [][]byte{addressFoo})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{addressFoo}, report.Exported)
+	assert.Empty(t, report.Skipped)
+
+	data, err := ioutil.ReadFile(filepath.Join(tempDir, relativePath))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("foo-data"), data)
+
+	checksum, err := ioutil.ReadFile(filepath.Join(tempDir,
+		relativePath+ChecksumExtension))
+	assert.NoError(t, err)
+	assert.Equal(t, "18607ec682de99e51e240f198d473b59b542e8926b815004d746a0435919454b"+
+		"  616464726573732d666f6f\n", string(checksum))
+
+	// Exporting again should skip what is there already and accumulate the rest
+	report, err = se.Export(store, [][]byte{addressFoo, addressBar})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{addressBar}, report.Exported)
+	assert.Equal(t, [][]byte{addressFoo}, report.Skipped)
+
+	manifest, err := se.ReadManifest()
+	assert.NoError(t, err)
+	if assert.Len(t, manifest.Blobs, 2) {
+		assert.Equal(t, addressBar, manifest.Blobs[0].Address)
+		assert.Equal(t, uint64(len("bar-data")), manifest.Blobs[0].Size)
+		assert.Equal(t, addressFoo, manifest.Blobs[1].Address)
+		assert.Equal(t, relativePath, manifest.Blobs[1].Path)
+	}
+
+	// Exporting again without checksums should keep those already written
+	se, err = NewStaticExporter(tempDir, storage.HexEncodingName,
+		Sharding{Depth: 2, Width: 2}, false)
+	assert.NoError(t, err)
+	report, err = se.Export(store, [][]byte{addressFoo})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{addressFoo}, report.Skipped)
+	manifest, err = se.ReadManifest()
+	assert.NoError(t, err)
+	if assert.Len(t, manifest.Blobs, 2) {
+		assert.Equal(t, "18607ec682de99e51e240f198d473b59b542e8926b815004d746a0435919454b",
+			manifest.Blobs[1].SHA256)
+	}
+
+	// Should not be able to reuse the directory with a different layout
+	se, err = NewStaticExporter(tempDir, storage.HexEncodingName,
+		Sharding{Depth: 1, Width: 2}, true)
+	assert.NoError(t, err)
+	_, err = se.Export(store, [][]byte{addressFoo})
+	assert.Error(t, err)
+
+	_, err = se.Export(store, [][]byte{bs("not-stored")})
+	assert.Error(t, err)
+}
+
+func TestStaticExporterRelativePath(t *testing.T) {
+	se := &StaticExporter{addressEncoding: storage.NewAddressEncoding(
+		func(bs []byte) string { return string(bs) }, nil)}
+	relativePath, err := se.RelativePath(bs("abcdef"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdef", relativePath)
+
+	se.sharding = Sharding{Depth: 3, Width: 2}
+	relativePath, err = se.RelativePath(bs("abcdef"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ab/cd/ef/abcdef", relativePath)
+
+	_, err = se.RelativePath(bs("abcde"))
+	assert.Error(t, err)
+
+	_, err = se.RelativePath(bs("ab/cdef"))
+	assert.Error(t, err)
+}
+
+func bs(s string) []byte {
+	return ([]byte)(s)
+}
@@ -0,0 +1,177 @@
+// Audits a store for bit rot and partial writes by checking every blob it
+// holds against its address
+package fsck
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/monax/hoard/core/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// The kinds of problem fsck can find with an entry in a store
+const (
+	// Listed by the store but not found when read
+	Missing = "missing"
+	// Data does not hash to its address (or could not be decrypted)
+	Corrupt = "corrupt"
+	// Not an address under the store's address encoding
+	Unparseable = "unparseable"
+	// Could not be read for some other reason so could not be checked
+	Unreadable = "unreadable"
+)
+
+type Options struct {
+	// If non-nil missing or corrupt entries are replaced with data read from
+	// this store provided it matches the address
+	RepairStore storage.Store
+	// Delete corrupt entries that are not repaired
+	DeleteCorrupt bool
+	// Maximum number of blobs checked per second, or unlimited if zero
+	Rate float64
+	// Checks blobs against their addresses. Defaults to storage.MatchesAddress
+	// which matches the content addressing used by Hoard.
+	MatchesAddress func(address, data []byte) bool
+}
+
+type Problem struct {
+	// The address of the entry or nil for unparseable entries
+	Address []byte `json:",omitempty"`
+	Kind    string
+	Error   string
+	// Whether the entry was replaced with good data from the repair store
+	Repaired bool `json:",omitempty"`
+	// Whether the corrupt entry was deleted
+	Deleted bool `json:",omitempty"`
+	// Why an attempted repair or deletion failed
+	ResolutionError string `json:",omitempty"`
+}
+
+// Whether the store no longer has this problem
+func (p *Problem) Resolved() bool {
+	return p.Repaired || p.Deleted
+}
+
+type Report struct {
+	Store string
+	// Number of entries listed by the store
+	Checked  int
+	Problems []*Problem
+	// Set if listing the store failed so the check is incomplete
+	Error string `json:",omitempty"`
+}
+
+// Whether the check was complete and any problems found were resolved
+func (r *Report) Clean() bool {
+	if r.Error != "" {
+		return false
+	}
+	for _, problem := range r.Problems {
+		if !problem.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Check every entry listed by store (which must support storage.Lister)
+// against its address. fn, if non-nil, is called with each problem as it is
+// found and may abort the check by returning an error.
+func Check(store storage.Store, options Options,
+	fn func(problem *Problem) error) (*Report, error) {
+
+	if options.MatchesAddress == nil {
+		options.MatchesAddress = storage.MatchesAddress
+	}
+	var throttle <-chan time.Time
+	if options.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / options.Rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+	report := &Report{Store: store.Name()}
+	err := storage.List(store, func(address []byte, err error) error {
+		report.Checked++
+		var problem *Problem
+		if err != nil {
+			problem = &Problem{
+				Kind:  Unparseable,
+				Error: err.Error(),
+			}
+		} else {
+			if throttle != nil {
+				<-throttle
+			}
+			problem = check(store, address, options)
+		}
+		if problem == nil {
+			return nil
+		}
+		report.Problems = append(report.Problems, problem)
+		if fn != nil {
+			return fn(problem)
+		}
+		return nil
+	})
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report, err
+}
+
+func check(store storage.Store, address []byte, options Options) *Problem {
+	data, err := store.Get(address)
+	problem := &Problem{Address: address}
+	switch {
+	case grpc.Code(err) == codes.NotFound:
+		problem.Kind = Missing
+		problem.Error = err.Error()
+	case grpc.Code(err) == codes.DataLoss:
+		problem.Kind = Corrupt
+		problem.Error = err.Error()
+	case err != nil:
+		problem.Kind = Unreadable
+		problem.Error = err.Error()
+		return problem
+	case !options.MatchesAddress(address, data):
+		problem.Kind = Corrupt
+		problem.Error = "Data does not match its address"
+	default:
+		return nil
+	}
+	if options.RepairStore != nil {
+		err = repair(store, options.RepairStore, address,
+			options.MatchesAddress)
+		if err == nil {
+			problem.Repaired = true
+			return problem
+		}
+		problem.ResolutionError = err.Error()
+	}
+	if options.DeleteCorrupt && problem.Kind == Corrupt {
+		err = storage.Delete(store, address)
+		if err == nil {
+			problem.Deleted = true
+			problem.ResolutionError = ""
+		} else {
+			problem.ResolutionError = err.Error()
+		}
+	}
+	return problem
+}
+
+func repair(store, repairStore storage.Store, address []byte,
+	matchesAddress func(address, data []byte) bool) error {
+
+	data, err := repairStore.Get(address)
+	if err != nil {
+		return fmt.Errorf("Could not read from %s: %s", repairStore.Name(), err)
+	}
+	if !matchesAddress(address, data) {
+		return fmt.Errorf("Data in %s does not match its address either",
+			repairStore.Name())
+	}
+	return store.Put(address, data)
+}
@@ -0,0 +1,134 @@
+package fsck
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/monax/hoard/core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	store := storage.NewMemoryStore()
+	goodAddress := storage.SHA256Addresser(bs("good"))
+	assert.NoError(t, store.Put(goodAddress, bs("good")))
+	corruptAddress := storage.SHA256Addresser(bs("corrupt"))
+	assert.NoError(t, store.Put(corruptAddress, bs("c0rrupt")))
+
+	var found []*Problem
+	report, err := Check(store, Options{}, func(problem *Problem) error {
+		found = append(found, problem)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	assert.False(t, report.Clean())
+	if assert.Len(t, report.Problems, 1) {
+		assert.Equal(t, found, report.Problems)
+		assert.Equal(t, corruptAddress, report.Problems[0].Address)
+		assert.Equal(t, Corrupt, report.Problems[0].Kind)
+		assert.False(t, report.Problems[0].Resolved())
+	}
+
+	reportJSON, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.Contains(t, string(reportJSON), `"Address":"`+
+		base64.StdEncoding.EncodeToString(corruptAddress)+`","Kind":"corrupt"`)
+
+	// Repair from a replica
+	repairStore := storage.NewMemoryStore()
+	assert.NoError(t, repairStore.Put(corruptAddress, bs("corrupt")))
+	report, err = Check(store, Options{RepairStore: repairStore}, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Problems, 1) {
+		assert.True(t, report.Problems[0].Repaired)
+	}
+	assert.True(t, report.Clean())
+	data, err := store.Get(corruptAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("corrupt"), data)
+
+	report, err = Check(store, Options{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Problems)
+}
+
+func TestCheckDelete(t *testing.T) {
+	store := storage.NewMemoryStore()
+	corruptAddress := storage.SHA256Addresser(bs("corrupt"))
+	assert.NoError(t, store.Put(corruptAddress, bs("c0rrupt")))
+
+	// The repair store does not have it so fall back to deleting
+	report, err := Check(store, Options{
+		RepairStore:   storage.NewMemoryStore(),
+		DeleteCorrupt: true,
+	}, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Problems, 1) {
+		assert.False(t, report.Problems[0].Repaired)
+		assert.True(t, report.Problems[0].Deleted)
+		assert.Empty(t, report.Problems[0].ResolutionError)
+	}
+	assert.True(t, report.Clean())
+	statInfo, err := store.Stat(corruptAddress)
+	assert.NoError(t, err)
+	assert.False(t, statInfo.Exists)
+}
+
+func TestCheckUnparseable(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "fsck_test")
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			panic(err)
+		}
+	}()
+	assert.NoError(t, err)
+
+	store, err := storage.NewFileSystemStore(tempDir, base64.URLEncoding)
+	assert.NoError(t, err)
+	goodAddress := storage.SHA256Addresser(bs("good"))
+	assert.NoError(t, store.Put(goodAddress, bs("good")))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tempDir, "not base64!"),
+		bs("stray"), 0644))
+
+	report, err := Check(store, Options{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	if assert.Len(t, report.Problems, 1) {
+		assert.Equal(t, Unparseable, report.Problems[0].Kind)
+		assert.Nil(t, report.Problems[0].Address)
+		assert.Contains(t, report.Problems[0].Error, "not base64!")
+	}
+}
+
+func TestCheckRate(t *testing.T) {
+	store := storage.NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		data := []byte{byte(i)}
+		assert.NoError(t, store.Put(storage.SHA256Addresser(data), data))
+	}
+	start := time.Now()
+	report, err := Check(store, Options{Rate: 50}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Checked)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+}
+
+func TestCheckUnlistable(t *testing.T) {
+	blindingStore, err := storage.NewBlindingStore(storage.NewMemoryStore(),
+		bs("a sixteen byte secret"))
+	assert.NoError(t, err)
+	report, err := Check(blindingStore, Options{}, nil)
+	assert.Error(t, err)
+	assert.False(t, report.Clean())
+}
+
+func bs(s string) []byte {
+	return []byte(s)
+}
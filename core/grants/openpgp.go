@@ -63,5 +63,5 @@ func OpenPGPGrantReference(grant string,
 	if err != nil {
 		return nil, err
 	}
-	return reference.FromPlaintext(string(bs)), nil
+	return reference.FromPlaintext(string(bs))
 }
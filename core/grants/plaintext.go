@@ -12,6 +12,6 @@ func SaltedPlaintextGrant(ref *reference.Ref, salt []byte) string {
 	return ref.Plaintext(salt)
 }
 
-func PlaintextGrantReference(grant string) *reference.Ref {
+func PlaintextGrantReference(grant string) (*reference.Ref, error) {
 	return reference.FromPlaintext(grant)
 }
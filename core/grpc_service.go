@@ -1,6 +1,9 @@
 package core
 
 import (
+	"time"
+
+	"github.com/monax/hoard/core/encryption"
 	"github.com/monax/hoard/core/reference"
 	"github.com/monax/hoard/core/storage"
 	"golang.org/x/net/context"
@@ -34,15 +37,22 @@ func (service *grpcService) Get(ctx context.Context,
 	}
 
 	return &Plaintext{
-		Data: data,
-		Salt: ref.Salt,
+		Data:    data,
+		Salt:    ref.Salt,
+		Suite:   ref.Suite,
+		Padding: ref.Padding,
+		Context: ref.Context,
 	}, nil
 }
 
 func (service *grpcService) Put(ctx context.Context,
 	plaintext *Plaintext) (*Reference, error) {
 
-	ref, err := service.des.Put(plaintext.Data, plaintext.Salt)
+	options, err := service.encryptionOptions(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := service.des.Put(plaintext.Data, plaintext.Salt, options)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +63,12 @@ func (service *grpcService) Put(ctx context.Context,
 func (service *grpcService) Encrypt(ctx context.Context,
 	plaintext *Plaintext) (*ReferenceAndCiphertext, error) {
 
-	ref, encryptedData, err := service.des.Encrypt(plaintext.Data, plaintext.Salt)
+	options, err := service.encryptionOptions(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	ref, encryptedData, err := service.des.Encrypt(plaintext.Data,
+		plaintext.Salt, options)
 	if err != nil {
 		return nil, err
 	}
@@ -74,8 +89,11 @@ func (service *grpcService) Decrypt(ctx context.Context,
 		return nil, err
 	}
 	return &Plaintext{
-		Data: data,
-		Salt: refAndCiphertext.Reference.Salt,
+		Data:    data,
+		Salt:    refAndCiphertext.Reference.Salt,
+		Suite:   refAndCiphertext.Reference.Suite,
+		Padding: refAndCiphertext.Reference.Padding,
+		Context: refAndCiphertext.Reference.Context,
 	}, nil
 }
 
@@ -91,6 +109,18 @@ func (service *grpcService) Push(ctx context.Context,
 	}, nil
 }
 
+func (service *grpcService) PushAt(ctx context.Context,
+	addressAndCiphertext *AddressAndCiphertext) (*Address, error) {
+	err := service.des.Store().PutAt(addressAndCiphertext.Address,
+		addressAndCiphertext.Ciphertext.EncryptedData)
+	if err != nil {
+		return nil, err
+	}
+	return &Address{
+		Address: addressAndCiphertext.Address,
+	}, nil
+}
+
 func (service *grpcService) Pull(ctx context.Context,
 	address *Address) (*Ciphertext, error) {
 
@@ -121,12 +151,49 @@ func (service *grpcService) Stat(ctx context.Context,
 	return pbStatInfo, nil
 }
 
+func (service *grpcService) Presign(ctx context.Context,
+	request *PresignRequest) (*PresignedURL, error) {
+
+	expiry := storage.DefaultPresignExpiry
+	if request.ExpirySeconds > 0 {
+		expiry = time.Duration(request.ExpirySeconds) * time.Second
+	}
+	url, err := service.des.Store().Presign(request.Address, expiry)
+	if err != nil {
+		return nil, err
+	}
+	return &PresignedURL{
+		Address:       request.Address,
+		Url:           url,
+		ExpirySeconds: uint64(expiry / time.Second),
+	}, nil
+}
+
+func (service *grpcService) Verify(ctx context.Context,
+	verifyCap *VerifyCap) (*Verification, error) {
+
+	err := service.des.Verify(hoardVerifyCap(verifyCap))
+	if err != nil {
+		return nil, err
+	}
+	return &Verification{
+		Address: verifyCap.Address,
+	}, nil
+}
+
 // From bitter experience it is better to decouple your serialisation types
 // from your object in-memory object model because they change for different
 // reasons So we bite the bullet and map between protobuf and hoard objects.
 
 func hoardRef(ref *Reference) *reference.Ref {
-	return reference.New(ref.Address, ref.SecretKey, ref.Salt)
+	return &reference.Ref{
+		Address:   ref.Address,
+		SecretKey: ref.SecretKey,
+		Salt:      ref.Salt,
+		Suite:     ref.Suite,
+		Padding:   ref.Padding,
+		Context:   ref.Context,
+	}
 }
 
 func protobufRef(ref *reference.Ref) *Reference {
@@ -134,7 +201,31 @@ func protobufRef(ref *reference.Ref) *Reference {
 		Address:   ref.Address,
 		SecretKey: ref.SecretKey,
 		Salt:      ref.Salt,
+		Suite:     ref.Suite,
+		Padding:   ref.Padding,
+		Context:   ref.Context,
+	}
+}
+
+func hoardVerifyCap(verifyCap *VerifyCap) *reference.VerifyCap {
+	return reference.NewVerifyCap(verifyCap.Address, verifyCap.KeyCommitment)
+}
+
+func (service *grpcService) encryptionOptions(
+	plaintext *Plaintext) (encryption.Options, error) {
+
+	convergenceSecret, err := service.des.ConvergenceSecret(plaintext.Namespace)
+	if err != nil {
+		return encryption.Options{}, err
 	}
+	return encryption.Options{
+		Suite:             plaintext.Suite,
+		Header:            plaintext.Header,
+		ConvergenceSecret: convergenceSecret,
+		Random:            plaintext.Random,
+		Padding:           plaintext.Padding,
+		Context:           plaintext.Context,
+	}, nil
 }
 
 func protobufStatInfo(statInfo *storage.StatInfo) *StatInfo {
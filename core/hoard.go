@@ -1,8 +1,7 @@
 package core
 
 import (
-	"crypto/sha256"
-	"hash"
+	"fmt"
 
 	"github.com/go-kit/kit/log"
 
@@ -16,15 +15,21 @@ import (
 // a GRPC service through grpcService which just plumbs this object into the
 // hoard.proto interface.
 type hoard struct {
-	store  storage.ContentAddressedStore
-	logger log.Logger
+	store              storage.ContentAddressedStore
+	hashAlgorithm      *storage.HashAlgorithm
+	convergenceSecrets map[string][]byte
+	logger             log.Logger
 }
 
 type DeterministicEncryptor interface {
-	// Encrypt data and return it along with reference
-	Encrypt(data, salt []byte) (ref *reference.Ref, encryptedData []byte, err error)
+	// Encrypt data with options and return it along with reference
+	Encrypt(data, salt []byte, options encryption.Options) (ref *reference.Ref,
+		encryptedData []byte, err error)
 	// Encrypt data and return it along with reference
 	Decrypt(ref *reference.Ref, encryptedData []byte) (data []byte, err error)
+	// Get the convergence secret for namespace to use in encryption.Options,
+	// the empty namespace gives the default secret (which may be nil)
+	ConvergenceSecret(namespace string) ([]byte, error)
 }
 
 type DeterministicEncryptedStore interface {
@@ -32,8 +37,11 @@ type DeterministicEncryptedStore interface {
 	// Get encrypted data from underlying storage at address and decrypt it using
 	// secretKey
 	Get(ref *reference.Ref) (data []byte, err error)
-	// Encrypt data and put it in underlying storage
-	Put(data, salt []byte) (*reference.Ref, error)
+	// Encrypt data with options and put it in underlying storage
+	Put(data, salt []byte, options encryption.Options) (*reference.Ref, error)
+	// Fetch the blob a verify capability refers to and check it hashes to its
+	// address and begins with the verify capability's key commitment
+	Verify(verifyCap *reference.VerifyCap) error
 	// Get the underlying ContentAddressedStore
 	Store() storage.ContentAddressedStore
 }
@@ -41,15 +49,27 @@ type DeterministicEncryptedStore interface {
 var _ DeterministicEncryptedStore = (*hoard)(nil)
 var _ DeterministicEncryptor = (*hoard)(nil)
 
-func NewHoard(store storage.Store, logger log.Logger) DeterministicEncryptedStore {
+// Create a Hoard that addresses blobs with multihashes using hashAlgorithm,
+// which is also used to derive convergent secret keys. If hashAlgorithm is nil
+// SHA256 is used. Blobs at bare SHA256 addresses from before multihashes were
+// introduced can still be read. The convergenceSecrets are keyed by namespace
+// with the default secret (if any) under the empty namespace.
+func NewHoard(store storage.Store, hashAlgorithm *storage.HashAlgorithm,
+	convergenceSecrets map[string][]byte,
+	logger log.Logger) DeterministicEncryptedStore {
 	if logger == nil {
 		logger = log.NewNopLogger()
 	}
+	if hashAlgorithm == nil {
+		hashAlgorithm, _ = storage.GetHashAlgorithm(storage.DefaultHashName)
+	}
 
 	return &hoard{
-		store: storage.NewContentAddressedStore(makeAddresser(sha256.New()),
+		store: storage.NewContentAddressedStore(hashAlgorithm.Addresser(),
 			storage.NewLoggingStore(storage.NewSyncStore(store), logger)),
-		logger: log.With(logger, "scope", "NewHoard"),
+		hashAlgorithm:      hashAlgorithm,
+		convergenceSecrets: convergenceSecrets,
+		logger:             log.With(logger, "scope", "NewHoard"),
 	}
 }
 
@@ -60,16 +80,14 @@ func (hrd *hoard) Get(ref *reference.Ref) ([]byte, error) {
 		return nil, err
 	}
 
-	data, err := encryption.Decrypt(ref.SecretKey, encryptedData, ref.Salt)
-	if err != nil {
-		return nil, err
-	}
-	return data, nil
+	return decrypt(ref, encryptedData)
 }
 
 // Encrypts data and stores it in underlying store and returns the address
-func (hrd *hoard) Put(data, salt []byte) (*reference.Ref, error) {
-	blob, err := encryption.Encrypt(data, salt)
+func (hrd *hoard) Put(data, salt []byte,
+	options encryption.Options) (*reference.Ref, error) {
+	blob, err := encryption.EncryptWithOptions(hrd.hashAlgorithm.New, data, salt,
+		options)
 	if err != nil {
 		return nil, err
 	}
@@ -77,38 +95,63 @@ func (hrd *hoard) Put(data, salt []byte) (*reference.Ref, error) {
 	if err != nil {
 		return nil, err
 	}
-	return reference.New(address, blob.SecretKey(), salt), nil
+	return newRef(address, blob, salt, options), nil
 }
 
 // Encrypt data and get reference
-func (hrd *hoard) Encrypt(data, salt []byte) (*reference.Ref, []byte, error) {
-	blob, err := encryption.Encrypt(data, salt)
+func (hrd *hoard) Encrypt(data, salt []byte,
+	options encryption.Options) (*reference.Ref, []byte, error) {
+	blob, err := encryption.EncryptWithOptions(hrd.hashAlgorithm.New, data, salt,
+		options)
 	if err != nil {
 		return nil, nil, err
 	}
 	address := hrd.store.Address(blob.EncryptedData())
-	return reference.New(address, blob.SecretKey(), salt), blob.EncryptedData(), nil
-
+	return newRef(address, blob, salt, options), blob.EncryptedData(), nil
 }
 
 // Decrypt data using reference
 func (hrd *hoard) Decrypt(ref *reference.Ref, encryptedData []byte) ([]byte, error) {
-	data, err := encryption.Decrypt(ref.SecretKey, encryptedData, ref.Salt)
+	return decrypt(ref, encryptedData)
+}
+
+func (hrd *hoard) Verify(verifyCap *reference.VerifyCap) error {
+	encryptedData, err := hrd.store.Get(verifyCap.Address)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return data, nil
+	return verifyCap.Verify(encryptedData)
+}
+
+func (hrd *hoard) ConvergenceSecret(namespace string) ([]byte, error) {
+	secret, ok := hrd.convergenceSecrets[namespace]
+	if !ok && namespace != "" {
+		return nil, fmt.Errorf("No convergence secret is configured for "+
+			"namespace '%s'", namespace)
+	}
+	return secret, nil
 }
 
 func (hrd *hoard) Store() storage.ContentAddressedStore {
 	return hrd.store
 }
 
-// Close in hasher
-func makeAddresser(hasher hash.Hash) func(data []byte) []byte {
-	return func(data []byte) []byte {
-		hasher.Reset()
-		hasher.Write(data)
-		return hasher.Sum(nil)
+func newRef(address []byte, blob encryption.EncryptedBlob, salt []byte,
+	options encryption.Options) *reference.Ref {
+	ref := reference.New(address, blob.SecretKey(), salt)
+	ref.Suite = blob.Suite()
+	ref.Padding = options.Padding
+	if len(options.Context) > 0 {
+		ref.Context = options.Context
 	}
+	return ref
+}
+
+func decrypt(ref *reference.Ref, encryptedData []byte) ([]byte, error) {
+	return encryption.DecryptWithOptions(ref.SecretKey, encryptedData, ref.Salt,
+		encryption.Options{
+			Suite:   ref.Suite,
+			Padding: ref.Padding,
+			Context: ref.Context,
+		})
 }
@@ -11,9 +11,14 @@ It has these top-level messages:
 	Reference
 	Plaintext
 	Ciphertext
+	VerifyCap
+	Verification
 	ReferenceAndCiphertext
+	AddressAndCiphertext
 	Address
 	StatInfo
+	PresignRequest
+	PresignedURL
 */
 package core
 
@@ -41,6 +46,14 @@ type Reference struct {
 	Address   []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 	SecretKey []byte `protobuf:"bytes,2,opt,name=secretKey,proto3" json:"secretKey,omitempty"`
 	Salt      []byte `protobuf:"bytes,3,opt,name=salt,proto3" json:"salt,omitempty"`
+	// The cipher suite the blob was encrypted with, empty means aes-256-gcm
+	Suite string `protobuf:"bytes,4,opt,name=suite" json:"suite,omitempty"`
+	// The padding scheme applied to the data before encryption, empty means
+	// none
+	Padding string `protobuf:"bytes,5,opt,name=padding" json:"padding,omitempty"`
+	// Caller-supplied context authenticated with the blob that must be
+	// presented to decrypt it
+	Context []byte `protobuf:"bytes,6,opt,name=context,proto3" json:"context,omitempty"`
 }
 
 func (m *Reference) Reset()                    { *m = Reference{} }
@@ -69,9 +82,48 @@ func (m *Reference) GetSalt() []byte {
 	return nil
 }
 
+func (m *Reference) GetSuite() string {
+	if m != nil {
+		return m.Suite
+	}
+	return ""
+}
+
+func (m *Reference) GetPadding() string {
+	if m != nil {
+		return m.Padding
+	}
+	return ""
+}
+
+func (m *Reference) GetContext() []byte {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
 type Plaintext struct {
 	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 	Salt []byte `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
+	// The cipher suite to encrypt with (one of aes-256-gcm,
+	// chacha20-poly1305, aes-256-gcm-committing), empty means aes-256-gcm
+	Suite string `protobuf:"bytes,3,opt,name=suite" json:"suite,omitempty"`
+	// Prefix the ciphertext with a header recording the format version and
+	// parameters it was encrypted with (readable without the secret key)
+	Header bool `protobuf:"varint,4,opt,name=header" json:"header,omitempty"`
+	// The namespace whose configured convergence secret is mixed into the
+	// secret key, empty means the default secret (if one is configured)
+	Namespace string `protobuf:"bytes,5,opt,name=namespace" json:"namespace,omitempty"`
+	// Encrypt with a random secret key instead of one derived from the data so
+	// that identical data is never deduplicated (namespace is then ignored)
+	Random bool `protobuf:"varint,6,opt,name=random" json:"random,omitempty"`
+	// Pad the data before encryption to hide its exact size (one of padme,
+	// power-of-two), empty means no padding
+	Padding string `protobuf:"bytes,7,opt,name=padding" json:"padding,omitempty"`
+	// Optional context (such as a tenant id or contract address) authenticated
+	// as additional data so the blob only decrypts with the same context
+	Context []byte `protobuf:"bytes,8,opt,name=context,proto3" json:"context,omitempty"`
 }
 
 func (m *Plaintext) Reset()                    { *m = Plaintext{} }
@@ -93,6 +145,48 @@ func (m *Plaintext) GetSalt() []byte {
 	return nil
 }
 
+func (m *Plaintext) GetSuite() string {
+	if m != nil {
+		return m.Suite
+	}
+	return ""
+}
+
+func (m *Plaintext) GetHeader() bool {
+	if m != nil {
+		return m.Header
+	}
+	return false
+}
+
+func (m *Plaintext) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Plaintext) GetRandom() bool {
+	if m != nil {
+		return m.Random
+	}
+	return false
+}
+
+func (m *Plaintext) GetPadding() string {
+	if m != nil {
+		return m.Padding
+	}
+	return ""
+}
+
+func (m *Plaintext) GetContext() []byte {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
 type Ciphertext struct {
 	EncryptedData []byte `protobuf:"bytes,1,opt,name=encryptedData,proto3" json:"encryptedData,omitempty"`
 }
@@ -109,6 +203,52 @@ func (m *Ciphertext) GetEncryptedData() []byte {
 	return nil
 }
 
+// A capability derived from a Reference to a blob encrypted with a
+// key-committing suite that allows the blob to be verified but not decrypted
+type VerifyCap struct {
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Commitment to the secret key (and so the hash of the plaintext) carried
+	// by the blob
+	KeyCommitment []byte `protobuf:"bytes,2,opt,name=keyCommitment,proto3" json:"keyCommitment,omitempty"`
+}
+
+func (m *VerifyCap) Reset()                    { *m = VerifyCap{} }
+func (m *VerifyCap) String() string            { return proto.CompactTextString(m) }
+func (*VerifyCap) ProtoMessage()               {}
+func (*VerifyCap) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *VerifyCap) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *VerifyCap) GetKeyCommitment() []byte {
+	if m != nil {
+		return m.KeyCommitment
+	}
+	return nil
+}
+
+type Verification struct {
+	// The address will be the same as the one passed in but is repeated to
+	// make result self-describing
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (m *Verification) Reset()                    { *m = Verification{} }
+func (m *Verification) String() string            { return proto.CompactTextString(m) }
+func (*Verification) ProtoMessage()               {}
+func (*Verification) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *Verification) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
 type ReferenceAndCiphertext struct {
 	Reference  *Reference  `protobuf:"bytes,1,opt,name=reference" json:"reference,omitempty"`
 	Ciphertext *Ciphertext `protobuf:"bytes,2,opt,name=ciphertext" json:"ciphertext,omitempty"`
@@ -117,7 +257,7 @@ type ReferenceAndCiphertext struct {
 func (m *ReferenceAndCiphertext) Reset()                    { *m = ReferenceAndCiphertext{} }
 func (m *ReferenceAndCiphertext) String() string            { return proto.CompactTextString(m) }
 func (*ReferenceAndCiphertext) ProtoMessage()               {}
-func (*ReferenceAndCiphertext) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*ReferenceAndCiphertext) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 func (m *ReferenceAndCiphertext) GetReference() *Reference {
 	if m != nil {
@@ -133,6 +273,30 @@ func (m *ReferenceAndCiphertext) GetCiphertext() *Ciphertext {
 	return nil
 }
 
+type AddressAndCiphertext struct {
+	Address    []byte      `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Ciphertext *Ciphertext `protobuf:"bytes,2,opt,name=ciphertext" json:"ciphertext,omitempty"`
+}
+
+func (m *AddressAndCiphertext) Reset()                    { *m = AddressAndCiphertext{} }
+func (m *AddressAndCiphertext) String() string            { return proto.CompactTextString(m) }
+func (*AddressAndCiphertext) ProtoMessage()               {}
+func (*AddressAndCiphertext) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *AddressAndCiphertext) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *AddressAndCiphertext) GetCiphertext() *Ciphertext {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
 type Address struct {
 	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 }
@@ -140,7 +304,7 @@ type Address struct {
 func (m *Address) Reset()                    { *m = Address{} }
 func (m *Address) String() string            { return proto.CompactTextString(m) }
 func (*Address) ProtoMessage()               {}
-func (*Address) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*Address) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *Address) GetAddress() []byte {
 	if m != nil {
@@ -166,7 +330,7 @@ type StatInfo struct {
 func (m *StatInfo) Reset()                    { *m = StatInfo{} }
 func (m *StatInfo) String() string            { return proto.CompactTextString(m) }
 func (*StatInfo) ProtoMessage()               {}
-func (*StatInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*StatInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *StatInfo) GetAddress() []byte {
 	if m != nil {
@@ -196,13 +360,79 @@ func (m *StatInfo) GetLocation() string {
 	return ""
 }
 
+type PresignRequest struct {
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// How long the URL should remain valid for, if omitted a default expiry
+	// will be used
+	ExpirySeconds uint64 `protobuf:"varint,2,opt,name=expirySeconds" json:"expirySeconds,omitempty"`
+}
+
+func (m *PresignRequest) Reset()                    { *m = PresignRequest{} }
+func (m *PresignRequest) String() string            { return proto.CompactTextString(m) }
+func (*PresignRequest) ProtoMessage()               {}
+func (*PresignRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *PresignRequest) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *PresignRequest) GetExpirySeconds() uint64 {
+	if m != nil {
+		return m.ExpirySeconds
+	}
+	return 0
+}
+
+type PresignedURL struct {
+	// The address will be the same as the one passed in but is repeated to
+	// make result self-describing
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Url     string `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
+	// How long the URL will remain valid for from when it was issued
+	ExpirySeconds uint64 `protobuf:"varint,3,opt,name=expirySeconds" json:"expirySeconds,omitempty"`
+}
+
+func (m *PresignedURL) Reset()                    { *m = PresignedURL{} }
+func (m *PresignedURL) String() string            { return proto.CompactTextString(m) }
+func (*PresignedURL) ProtoMessage()               {}
+func (*PresignedURL) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *PresignedURL) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *PresignedURL) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *PresignedURL) GetExpirySeconds() uint64 {
+	if m != nil {
+		return m.ExpirySeconds
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Reference)(nil), "core.Reference")
 	proto.RegisterType((*Plaintext)(nil), "core.Plaintext")
 	proto.RegisterType((*Ciphertext)(nil), "core.Ciphertext")
+	proto.RegisterType((*VerifyCap)(nil), "core.VerifyCap")
+	proto.RegisterType((*Verification)(nil), "core.Verification")
 	proto.RegisterType((*ReferenceAndCiphertext)(nil), "core.ReferenceAndCiphertext")
+	proto.RegisterType((*AddressAndCiphertext)(nil), "core.AddressAndCiphertext")
 	proto.RegisterType((*Address)(nil), "core.Address")
 	proto.RegisterType((*StatInfo)(nil), "core.StatInfo")
+	proto.RegisterType((*PresignRequest)(nil), "core.PresignRequest")
+	proto.RegisterType((*PresignedURL)(nil), "core.PresignedURL")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -430,9 +660,24 @@ type StorageClient interface {
 	Pull(ctx context.Context, in *Address, opts ...grpc.CallOption) (*Ciphertext, error)
 	// Insert the (presumably) encrypted data provided and get the its address.
 	Push(ctx context.Context, in *Ciphertext, opts ...grpc.CallOption) (*Address, error)
+	// Insert encrypted data at an address that need not be made with this
+	// Hoard's hash algorithm (such as a legacy SHA256 address) provided the
+	// data matches it. Used to import archives from other Hoards.
+	PushAt(ctx context.Context, in *AddressAndCiphertext, opts ...grpc.CallOption) (*Address, error)
 	// Get some information about the encrypted blob stored at an address,
 	// including whether it exists.
 	Stat(ctx context.Context, in *Address, opts ...grpc.CallOption) (*StatInfo, error)
+	// Get a time-limited URL from which the encrypted blob stored at an
+	// address can be fetched directly from the storage backend without going
+	// through Hoard. Returns an Unimplemented error if the storage backend
+	// does not support presigned URLs.
+	Presign(ctx context.Context, in *PresignRequest, opts ...grpc.CallOption) (*PresignedURL, error)
+	// Fetch the encrypted blob a verify capability refers to and check that
+	// it hashes to its address and begins with the key commitment in the
+	// verify capability. The authentication tag cannot be checked without the
+	// secret key, so this does not show the blob decrypts. Returns an error if
+	// verification fails.
+	Verify(ctx context.Context, in *VerifyCap, opts ...grpc.CallOption) (*Verification, error)
 }
 
 type storageClient struct {
@@ -461,6 +706,15 @@ func (c *storageClient) Push(ctx context.Context, in *Ciphertext, opts ...grpc.C
 	return out, nil
 }
 
+func (c *storageClient) PushAt(ctx context.Context, in *AddressAndCiphertext, opts ...grpc.CallOption) (*Address, error) {
+	out := new(Address)
+	err := grpc.Invoke(ctx, "/core.Storage/PushAt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *storageClient) Stat(ctx context.Context, in *Address, opts ...grpc.CallOption) (*StatInfo, error) {
 	out := new(StatInfo)
 	err := grpc.Invoke(ctx, "/core.Storage/Stat", in, out, c.cc, opts...)
@@ -470,6 +724,24 @@ func (c *storageClient) Stat(ctx context.Context, in *Address, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *storageClient) Presign(ctx context.Context, in *PresignRequest, opts ...grpc.CallOption) (*PresignedURL, error) {
+	out := new(PresignedURL)
+	err := grpc.Invoke(ctx, "/core.Storage/Presign", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) Verify(ctx context.Context, in *VerifyCap, opts ...grpc.CallOption) (*Verification, error) {
+	out := new(Verification)
+	err := grpc.Invoke(ctx, "/core.Storage/Verify", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Storage service
 
 type StorageServer interface {
@@ -477,9 +749,24 @@ type StorageServer interface {
 	Pull(context.Context, *Address) (*Ciphertext, error)
 	// Insert the (presumably) encrypted data provided and get the its address.
 	Push(context.Context, *Ciphertext) (*Address, error)
+	// Insert encrypted data at an address that need not be made with this
+	// Hoard's hash algorithm (such as a legacy SHA256 address) provided the
+	// data matches it. Used to import archives from other Hoards.
+	PushAt(context.Context, *AddressAndCiphertext) (*Address, error)
 	// Get some information about the encrypted blob stored at an address,
 	// including whether it exists.
 	Stat(context.Context, *Address) (*StatInfo, error)
+	// Get a time-limited URL from which the encrypted blob stored at an
+	// address can be fetched directly from the storage backend without going
+	// through Hoard. Returns an Unimplemented error if the storage backend
+	// does not support presigned URLs.
+	Presign(context.Context, *PresignRequest) (*PresignedURL, error)
+	// Fetch the encrypted blob a verify capability refers to and check that
+	// it hashes to its address and begins with the key commitment in the
+	// verify capability. The authentication tag cannot be checked without the
+	// secret key, so this does not show the blob decrypts. Returns an error if
+	// verification fails.
+	Verify(context.Context, *VerifyCap) (*Verification, error)
 }
 
 func RegisterStorageServer(s *grpc.Server, srv StorageServer) {
@@ -522,6 +809,24 @@ func _Storage_Push_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Storage_PushAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressAndCiphertext)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).PushAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/core.Storage/PushAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).PushAt(ctx, req.(*AddressAndCiphertext))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Storage_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Address)
 	if err := dec(in); err != nil {
@@ -540,6 +845,42 @@ func _Storage_Stat_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Storage_Presign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Presign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/core.Storage/Presign",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Presign(ctx, req.(*PresignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyCap)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/core.Storage/Verify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Verify(ctx, req.(*VerifyCap))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Storage_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "core.Storage",
 	HandlerType: (*StorageServer)(nil),
@@ -552,10 +893,22 @@ var _Storage_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Push",
 			Handler:    _Storage_Push_Handler,
 		},
+		{
+			MethodName: "PushAt",
+			Handler:    _Storage_PushAt_Handler,
+		},
 		{
 			MethodName: "Stat",
 			Handler:    _Storage_Stat_Handler,
 		},
+		{
+			MethodName: "Presign",
+			Handler:    _Storage_Presign_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _Storage_Verify_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "hoard.proto",
@@ -564,30 +917,45 @@ var _Storage_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("hoard.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 387 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x93, 0xc1, 0x6b, 0xea, 0x40,
-	0x10, 0xc6, 0x89, 0x06, 0x63, 0xc6, 0xe7, 0x7b, 0x8f, 0x3d, 0x48, 0x08, 0x1e, 0x24, 0x6d, 0xd1,
-	0x4b, 0xa5, 0xc4, 0x8b, 0x57, 0xd1, 0x52, 0x4a, 0x2f, 0x12, 0x0f, 0x3d, 0xf5, 0xb0, 0x4d, 0xc6,
-	0x1a, 0x08, 0x59, 0xd9, 0x5d, 0x41, 0x7b, 0x2a, 0xfd, 0xcb, 0x8b, 0xbb, 0x71, 0xa3, 0x09, 0xf5,
-	0xb6, 0x33, 0xf3, 0xfd, 0x36, 0x3b, 0xdf, 0x47, 0xa0, 0xb3, 0x61, 0x94, 0x27, 0xe3, 0x2d, 0x67,
-	0x92, 0x11, 0x3b, 0x66, 0x1c, 0x83, 0x57, 0x70, 0x23, 0x5c, 0x23, 0xc7, 0x3c, 0x46, 0xe2, 0x81,
-	0x43, 0x93, 0x84, 0xa3, 0x10, 0x9e, 0x35, 0xb0, 0x46, 0x7f, 0xa2, 0x53, 0x49, 0xfa, 0xe0, 0x0a,
-	0x8c, 0x39, 0xca, 0x17, 0x3c, 0x78, 0x0d, 0x35, 0x2b, 0x1b, 0x84, 0x80, 0x2d, 0x68, 0x26, 0xbd,
-	0xa6, 0x1a, 0xa8, 0x73, 0x30, 0x01, 0x77, 0x99, 0xd1, 0x34, 0x97, 0xb8, 0x97, 0x47, 0x41, 0x42,
-	0x25, 0x2d, 0x6e, 0x55, 0x67, 0x03, 0x35, 0xce, 0xa0, 0x10, 0x60, 0x9e, 0x6e, 0x37, 0xc8, 0x15,
-	0x75, 0x0b, 0x5d, 0xcc, 0x63, 0x7e, 0xd8, 0x4a, 0x4c, 0x16, 0x25, 0x7e, 0xd9, 0x0c, 0x0e, 0xd0,
-	0x33, 0x1b, 0xcc, 0xf2, 0xe4, 0x8c, 0xbf, 0x07, 0x97, 0x9f, 0x26, 0x8a, 0xed, 0x84, 0xff, 0xc6,
-	0xc7, 0xad, 0xc7, 0x06, 0x88, 0x4a, 0x05, 0x79, 0x00, 0x88, 0x0d, 0xac, 0x9e, 0xd5, 0x09, 0xff,
-	0x6b, 0x7d, 0x79, 0x69, 0x74, 0xa6, 0x09, 0x6e, 0xc0, 0x99, 0x15, 0x06, 0xfd, 0x6a, 0x5d, 0x90,
-	0x41, 0x7b, 0x25, 0xa9, 0x7c, 0xce, 0xd7, 0xec, 0x8a, 0xc1, 0x3d, 0x68, 0xe1, 0x3e, 0x15, 0x52,
-	0xa8, 0x0f, 0xb7, 0xa3, 0xa2, 0x52, 0x2e, 0xa5, 0x9f, 0xa8, 0xac, 0xb5, 0x23, 0x75, 0x26, 0x3e,
-	0xb4, 0x33, 0x16, 0x53, 0x99, 0xb2, 0xdc, 0xb3, 0x07, 0xd6, 0xc8, 0x8d, 0x4c, 0x1d, 0xbe, 0x81,
-	0x3b, 0xcf, 0x90, 0x6a, 0x03, 0x86, 0xd0, 0x7c, 0x42, 0x49, 0xaa, 0x4b, 0xfb, 0x45, 0xa3, 0xcc,
-	0x67, 0x08, 0xcd, 0xe5, 0xce, 0x08, 0x4d, 0xdf, 0xaf, 0x92, 0xe1, 0x97, 0x05, 0xf0, 0xa8, 0xed,
-	0x4f, 0x59, 0x4e, 0xa6, 0xe0, 0x14, 0x55, 0x9d, 0xed, 0x57, 0xd8, 0xcb, 0x6c, 0xa6, 0xe0, 0x2c,
-	0x50, 0x93, 0x57, 0x85, 0xb5, 0xb7, 0x86, 0xdf, 0x16, 0x38, 0x2b, 0xc9, 0x38, 0xfd, 0x40, 0x32,
-	0x04, 0x7b, 0xb9, 0xcb, 0x32, 0xd2, 0xd5, 0xa2, 0x22, 0x0c, 0xbf, 0x96, 0x9a, 0x16, 0x8a, 0x0d,
-	0xa9, 0x4d, 0xfc, 0x4b, 0x94, 0xdc, 0x81, 0x7d, 0x4c, 0xab, 0x7a, 0xe3, 0x5f, 0x5d, 0x9e, 0x82,
-	0x7c, 0x6f, 0xa9, 0x7f, 0x68, 0xf2, 0x13, 0x00, 0x00, 0xff, 0xff, 0xd8, 0x6a, 0xd7, 0x0f, 0x52,
-	0x03, 0x00, 0x00,
+	// 626 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0x4f, 0x4f, 0xdc, 0x3e,
+	0x10, 0xd5, 0xb2, 0x61, 0xb3, 0x19, 0xfe, 0xfc, 0x90, 0x85, 0x50, 0x14, 0x71, 0x40, 0xf9, 0x51,
+	0xc1, 0x05, 0x54, 0x2d, 0x17, 0xae, 0x68, 0xa9, 0xaa, 0x8a, 0x1e, 0x56, 0x46, 0xed, 0xad, 0x52,
+	0x4d, 0x3c, 0xb0, 0x56, 0xb3, 0x76, 0xea, 0x78, 0x25, 0xb6, 0xa7, 0x7e, 0x8f, 0x9e, 0xfa, 0x79,
+	0xfa, 0xa5, 0xaa, 0xd8, 0xde, 0xec, 0x26, 0x0b, 0x91, 0x7a, 0xf3, 0x1b, 0xcf, 0xcc, 0x7b, 0x63,
+	0xbf, 0x38, 0xb0, 0x33, 0x55, 0x4c, 0xf3, 0xcb, 0x42, 0x2b, 0xa3, 0x48, 0x90, 0x29, 0x8d, 0xe9,
+	0xef, 0x1e, 0x44, 0x14, 0x1f, 0x51, 0xa3, 0xcc, 0x90, 0xc4, 0x10, 0x32, 0xce, 0x35, 0x96, 0x65,
+	0xdc, 0x3b, 0xe9, 0x9d, 0xef, 0xd2, 0x25, 0x24, 0xc7, 0x10, 0x95, 0x98, 0x69, 0x34, 0x77, 0xb8,
+	0x88, 0xb7, 0xec, 0xde, 0x2a, 0x40, 0x08, 0x04, 0x25, 0xcb, 0x4d, 0xdc, 0xb7, 0x1b, 0x76, 0x4d,
+	0x0e, 0x61, 0xbb, 0x9c, 0x0b, 0x83, 0x71, 0x70, 0xd2, 0x3b, 0x8f, 0xa8, 0x03, 0x15, 0x43, 0xc1,
+	0x38, 0x17, 0xf2, 0x29, 0xde, 0xb6, 0xf1, 0x25, 0xac, 0x76, 0x32, 0x25, 0x0d, 0x3e, 0x9b, 0x78,
+	0xe0, 0xb8, 0x3d, 0x4c, 0xff, 0xf4, 0x20, 0x9a, 0xe4, 0x4c, 0x58, 0x54, 0x71, 0x71, 0x66, 0x98,
+	0x17, 0x68, 0xd7, 0x35, 0xff, 0xd6, 0x4b, 0xfc, 0xfd, 0x75, 0xfe, 0x23, 0x18, 0x4c, 0x91, 0x71,
+	0xd4, 0x56, 0xd6, 0x90, 0x7a, 0x54, 0xcd, 0x27, 0xd9, 0x0c, 0xcb, 0x82, 0x65, 0xe8, 0x95, 0xad,
+	0x02, 0x55, 0x95, 0x66, 0x92, 0xab, 0x99, 0x95, 0x36, 0xa4, 0x1e, 0xad, 0x4f, 0x13, 0xbe, 0x3a,
+	0xcd, 0xb0, 0x39, 0xcd, 0x08, 0x60, 0x2c, 0x8a, 0x29, 0x6a, 0x3b, 0xcd, 0x29, 0xec, 0xa1, 0xcc,
+	0xf4, 0xa2, 0x30, 0xc8, 0x6f, 0x57, 0x63, 0x35, 0x83, 0xe9, 0x1d, 0x44, 0x9f, 0x51, 0x8b, 0xc7,
+	0xc5, 0x98, 0x15, 0x1d, 0x97, 0x74, 0x0a, 0x7b, 0xdf, 0x70, 0x31, 0x56, 0xb3, 0x99, 0x30, 0x33,
+	0x94, 0xcb, 0xf3, 0x68, 0x06, 0xd3, 0x73, 0xd8, 0xb5, 0xcd, 0x44, 0xc6, 0x8c, 0x50, 0xf2, 0xf5,
+	0x7e, 0xe9, 0x02, 0x8e, 0x6a, 0x6f, 0xdc, 0x48, 0xbe, 0x26, 0xfb, 0x02, 0x22, 0xbd, 0xdc, 0xb1,
+	0x55, 0x3b, 0xa3, 0xff, 0x2e, 0x2b, 0x43, 0x5d, 0xd6, 0x05, 0x74, 0x95, 0x41, 0xde, 0x02, 0x64,
+	0x75, 0xb1, 0x55, 0xb5, 0x33, 0x3a, 0x70, 0xf9, 0xab, 0xa6, 0x74, 0x2d, 0x27, 0x7d, 0x80, 0xc3,
+	0x1b, 0xa7, 0xa2, 0x49, 0xfc, 0xfa, 0xf0, 0xff, 0xce, 0xf1, 0x3f, 0x84, 0x9e, 0xa3, 0xe3, 0x0c,
+	0x72, 0x18, 0xde, 0x1b, 0x66, 0x3e, 0xc8, 0x47, 0xd5, 0x41, 0x7e, 0x04, 0x03, 0x7c, 0x16, 0xa5,
+	0x29, 0x2d, 0xf1, 0x90, 0x7a, 0x64, 0x8d, 0x29, 0x7e, 0x38, 0x0f, 0x06, 0xd4, 0xae, 0x49, 0x02,
+	0xc3, 0x5c, 0xb9, 0xb3, 0xf7, 0xdf, 0x46, 0x8d, 0xd3, 0x09, 0xec, 0x4f, 0x34, 0x96, 0xe2, 0x49,
+	0x52, 0xfc, 0x3e, 0xc7, 0xd2, 0x74, 0xdf, 0x36, 0x3e, 0x17, 0x42, 0x2f, 0xee, 0x31, 0x53, 0x92,
+	0x3b, 0xea, 0x80, 0x36, 0x83, 0xe9, 0x57, 0xd8, 0xf5, 0x1d, 0x91, 0x7f, 0xa2, 0x1f, 0x3b, 0xfa,
+	0x1d, 0x40, 0x7f, 0xae, 0x73, 0xdb, 0x25, 0xa2, 0xd5, 0x72, 0x93, 0xa1, 0xff, 0x02, 0xc3, 0xe8,
+	0x0b, 0x44, 0xe3, 0x1c, 0x99, 0xbb, 0x9f, 0x33, 0xe8, 0xbf, 0x47, 0x43, 0xda, 0x66, 0x48, 0x7c,
+	0x60, 0xf5, 0x19, 0x9f, 0x41, 0x7f, 0x32, 0x37, 0xa4, 0x1d, 0x4f, 0xda, 0x95, 0xa3, 0x9f, 0x3d,
+	0x80, 0x77, 0xee, 0x6b, 0xa8, 0xdc, 0x7a, 0x0d, 0xa1, 0x47, 0x9b, 0xb5, 0xc7, 0xad, 0xda, 0xa6,
+	0x75, 0xae, 0x21, 0xbc, 0x45, 0x57, 0xd9, 0x99, 0xb8, 0xa1, 0x75, 0xf4, 0x6b, 0x0b, 0xc2, 0x7b,
+	0xa3, 0x34, 0x7b, 0x42, 0x72, 0x06, 0xc1, 0x64, 0x9e, 0xe7, 0x64, 0xcf, 0x25, 0x79, 0x03, 0x25,
+	0x1b, 0x4e, 0x73, 0x89, 0xe5, 0x94, 0x6c, 0xec, 0x24, 0xcd, 0x52, 0x72, 0x05, 0x83, 0x2a, 0xf1,
+	0xc6, 0x90, 0xa4, 0xb1, 0xd1, 0x14, 0xd5, 0x2a, 0x7a, 0x03, 0x41, 0x65, 0xcb, 0xb6, 0x8c, 0x7d,
+	0x07, 0x6b, 0xc7, 0x5e, 0x41, 0xe8, 0x6f, 0x9f, 0x1c, 0xfa, 0xa9, 0x1a, 0xf6, 0x4a, 0x48, 0x23,
+	0xea, 0x2c, 0x72, 0x01, 0x03, 0xf7, 0xda, 0x2c, 0x4f, 0xb8, 0x7e, 0x7b, 0x12, 0xb2, 0x16, 0xf0,
+	0xef, 0xc7, 0xc3, 0xc0, 0xfe, 0x4f, 0xae, 0xfe, 0x0e, 0x00, 0x56, 0xff, 0xc4, 0x19, 0x5e, 0x06,
+	0x00, 0x00,
 }
@@ -4,16 +4,17 @@ import (
 	"testing"
 
 	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/encryption"
 	"github.com/monax/hoard/core/reference"
 	"github.com/monax/hoard/core/storage"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestDeterministicEncryptedStore(t *testing.T) {
-	hrd := NewHoard(storage.NewMemoryStore(), log.NewNopLogger())
+	hrd := NewHoard(storage.NewMemoryStore(), nil, nil, log.NewNopLogger())
 	bunsIn := bs("hot buns")
 
-	ref, err := hrd.Put(bunsIn, nil)
+	ref, err := hrd.Put(bunsIn, nil, encryption.Options{})
 	assert.NoError(t, err)
 
 	bunsOut, err := hrd.Get(ref)
@@ -30,7 +31,8 @@ func TestDeterministicEncryptedStore(t *testing.T) {
 	assert.Equal(t, uint64(len(bunsIn))+16, statInfo.Size)
 
 	loc := hrd.Store().Location(ref.Address)
-	assert.Equal(t, "memfs://2768b87bc22a7f0f20eb268937a00d682bc5dc21a62badf1d24c78633436a1b7", loc)
+	assert.Equal(t, "memfs://1220"+
+		"2768b87bc22a7f0f20eb268937a00d682bc5dc21a62badf1d24c78633436a1b7", loc)
 
 	// flip LSB of first byte of address to get an non-existent address
 	ref.Address[0] = ref.Address[0] ^ 1
@@ -39,6 +41,200 @@ func TestDeterministicEncryptedStore(t *testing.T) {
 	assert.False(t, statInfo.Exists)
 }
 
+func TestHashAlgorithms(t *testing.T) {
+	for _, name := range []string{storage.SHA2_256HashName,
+		storage.SHA3_256HashName, storage.BLAKE2b256HashName} {
+		hashAlgorithm, err := storage.GetHashAlgorithm(name)
+		assert.NoError(t, err)
+		hrd := NewHoard(storage.NewMemoryStore(), hashAlgorithm, nil, nil)
+		ref, ciphertext, err := hrd.Encrypt(bs("hot buns"), nil, encryption.Options{})
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, hashAlgorithm.Multihash(ciphertext), ref.Address)
+		}
+	}
+}
+
+func TestSuites(t *testing.T) {
+	hrd := NewHoard(storage.NewMemoryStore(), nil, nil, nil)
+	bunsIn := bs("hot buns")
+	for _, suite := range encryption.SuiteNames() {
+		ref, err := hrd.Put(bunsIn, bs("salt"), encryption.Options{Suite: suite})
+		if assert.NoError(t, err, suite) {
+			assert.Equal(t, suite, ref.Suite)
+			bunsOut, err := hrd.Get(ref)
+			assert.NoError(t, err, suite)
+			assert.Equal(t, bunsIn, bunsOut, suite)
+		}
+	}
+
+	// References without a suite decrypt as AES-256-GCM
+	ref, err := hrd.Put(bunsIn, nil,
+		encryption.Options{Suite: encryption.AES256GCMSuite})
+	assert.NoError(t, err)
+	ref.Suite = ""
+	bunsOut, err := hrd.Get(ref)
+	assert.NoError(t, err)
+	assert.Equal(t, bunsIn, bunsOut)
+
+	_, err = hrd.Put(bunsIn, nil, encryption.Options{Suite: "rot13"})
+	assert.Error(t, err)
+}
+
+func TestConvergenceSecrets(t *testing.T) {
+	hrd := NewHoard(storage.NewMemoryStore(), nil, map[string][]byte{
+		"":      bs("default secret"),
+		"alice": bs("alice secret"),
+	}, nil)
+	defaultSecret, err := hrd.ConvergenceSecret("")
+	assert.NoError(t, err)
+	assert.Equal(t, bs("default secret"), defaultSecret)
+	aliceSecret, err := hrd.ConvergenceSecret("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, bs("alice secret"), aliceSecret)
+	_, err = hrd.ConvergenceSecret("bob")
+	assert.Error(t, err)
+
+	ref, err := hrd.Put(bs("hot buns"), nil,
+		encryption.Options{ConvergenceSecret: aliceSecret})
+	assert.NoError(t, err)
+	bunsOut, err := hrd.Get(ref)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("hot buns"), bunsOut)
+	unkeyedRef, err := hrd.Put(bs("hot buns"), nil, encryption.Options{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, unkeyedRef.Address, ref.Address)
+
+	// Without secrets there is no default and namespaces are unknown
+	hrd = NewHoard(storage.NewMemoryStore(), nil, nil, nil)
+	defaultSecret, err = hrd.ConvergenceSecret("")
+	assert.NoError(t, err)
+	assert.Nil(t, defaultSecret)
+	_, err = hrd.ConvergenceSecret("alice")
+	assert.Error(t, err)
+}
+
+func TestRandomPut(t *testing.T) {
+	hrd := NewHoard(storage.NewMemoryStore(), nil, nil, nil)
+	bunsIn := bs("hot buns")
+	ref1, err := hrd.Put(bunsIn, nil, encryption.Options{Random: true})
+	assert.NoError(t, err)
+	ref2, err := hrd.Put(bunsIn, nil, encryption.Options{Random: true})
+	assert.NoError(t, err)
+	assert.NotEqual(t, ref1.Address, ref2.Address)
+
+	for _, ref := range []*reference.Ref{ref1, ref2} {
+		bunsOut, err := hrd.Get(ref)
+		assert.NoError(t, err)
+		assert.Equal(t, bunsIn, bunsOut)
+		// Still content-addressed by the ciphertext
+		encryptedData, err := hrd.Store().Get(ref.Address)
+		assert.NoError(t, err)
+		assert.True(t, storage.MatchesAddress(ref.Address, encryptedData))
+	}
+}
+
+func TestPaddedPut(t *testing.T) {
+	hrd := NewHoard(storage.NewMemoryStore(), nil, nil, nil)
+	bunsIn := bs("hot buns")
+	ref, err := hrd.Put(bunsIn, nil,
+		encryption.Options{Padding: encryption.PowerOfTwoPadding})
+	assert.NoError(t, err)
+	assert.Equal(t, encryption.PowerOfTwoPadding, ref.Padding)
+
+	bunsOut, err := hrd.Get(ref)
+	assert.NoError(t, err)
+	assert.Equal(t, bunsIn, bunsOut)
+
+	// Stat reports the stored size: 8 bytes and marker padded to 16 bytes plus
+	// the GCM tag
+	statInfo, err := hrd.Store().Stat(ref.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16+16), statInfo.Size)
+}
+
+func TestContextPut(t *testing.T) {
+	hrd := NewHoard(storage.NewMemoryStore(), nil, nil, nil)
+	bunsIn := bs("hot buns")
+	ref, err := hrd.Put(bunsIn, nil,
+		encryption.Options{Context: bs("contract address")})
+	assert.NoError(t, err)
+	assert.Equal(t, bs("contract address"), ref.Context)
+
+	bunsOut, err := hrd.Get(ref)
+	assert.NoError(t, err)
+	assert.Equal(t, bunsIn, bunsOut)
+
+	ref.Context = bs("other contract address")
+	_, err = hrd.Get(ref)
+	assert.Error(t, err)
+	ref.Context = nil
+	_, err = hrd.Get(ref)
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	store := storage.NewMemoryStore()
+	hrd := NewHoard(store, nil, nil, nil)
+
+	// Suites that do not commit to their key have no verify capability
+	ref, err := hrd.Put(bs("hot buns"), nil, encryption.Options{})
+	assert.NoError(t, err)
+	_, err = ref.VerifyCap()
+	assert.Error(t, err)
+	assert.Error(t, hrd.Verify(reference.NewVerifyCap(ref.Address, nil)))
+
+	for _, options := range []encryption.Options{
+		{Suite: encryption.AES256GCMCommittingSuite},
+		{Suite: encryption.AES256GCMCommittingSuite, Header: true},
+	} {
+		ref, err := hrd.Put(bs("hot buns"), nil, options)
+		assert.NoError(t, err)
+		verifyCap, err := ref.VerifyCap()
+		assert.NoError(t, err)
+		assert.NoError(t, hrd.Verify(verifyCap), "%v", options)
+
+		// A blob at an address for a different plaintext's commitment
+		otherRef, err := hrd.Put(bs("cold buns"), nil, options)
+		assert.NoError(t, err)
+		otherCap, err := otherRef.VerifyCap()
+		assert.NoError(t, err)
+		assert.Error(t, hrd.Verify(reference.NewVerifyCap(ref.Address,
+			otherCap.KeyCommitment)))
+
+		// Corrupt the stored blob
+		data, err := store.Get(ref.Address)
+		assert.NoError(t, err)
+		data[len(data)-1] ^= 1
+		assert.NoError(t, store.Put(ref.Address, data))
+		assert.Error(t, hrd.Verify(verifyCap))
+	}
+}
+
+func TestLegacyAddress(t *testing.T) {
+	store := storage.NewMemoryStore()
+	hrd := NewHoard(store, nil, nil, nil)
+	// Blobs stored before multihash addresses were introduced
+	legacyAddress := storage.SHA256Addresser(bs("ciphertext"))
+	assert.NoError(t, store.Put(legacyAddress, bs("ciphertext")))
+
+	statInfo, err := hrd.Store().Stat(legacyAddress)
+	assert.NoError(t, err)
+	assert.True(t, statInfo.Exists)
+	data, err := hrd.Store().Get(legacyAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("ciphertext"), data)
+	assert.True(t, storage.MatchesAddress(legacyAddress, data))
+
+	// Legacy blobs can be imported at their address but not at an address
+	// they do not match
+	importedAddress := storage.SHA256Addresser(bs("imported ciphertext"))
+	assert.NoError(t, hrd.Store().PutAt(importedAddress, bs("imported ciphertext")))
+	data, err = hrd.Store().Get(importedAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("imported ciphertext"), data)
+	assert.Error(t, hrd.Store().PutAt(importedAddress, bs("other ciphertext")))
+}
+
 func bs(s string) []byte {
 	return ([]byte)(s)
 }
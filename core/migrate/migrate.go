@@ -0,0 +1,225 @@
+// Copies every blob from one store to another so that data can be moved
+// between backends or address encodings without ad hoc scripts
+package migrate
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/monax/hoard/core/storage"
+)
+
+// Number of workers used when none is specified
+const DefaultWorkers = 4
+
+type Options struct {
+	// Number of blobs copied concurrently
+	Workers int
+	// If non-empty the base64-encoded address of each blob that has been
+	// copied or found already present is appended to this file, and any
+	// addresses already in it are skipped, so that an interrupted migration
+	// can be resumed by running it again with the same checkpoint file
+	CheckpointFile string
+	// Checks blobs read from the source against their addresses. Defaults to
+	// storage.MatchesAddress which matches the content addressing used by
+	// Hoard.
+	MatchesAddress func(address, data []byte) bool
+}
+
+type Failure struct {
+	// The address of the blob that could not be migrated, or nil if the
+	// source listed an entry that is not an address
+	Address []byte
+	Err     error
+}
+
+func (f *Failure) Error() string {
+	if f.Address == nil {
+		return f.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", base64.StdEncoding.EncodeToString(f.Address),
+		f.Err)
+}
+
+type Report struct {
+	Copied [][]byte
+	// Addresses already present in the destination or in the checkpoint file
+	Skipped [][]byte
+	Failed  []*Failure
+}
+
+// Copy every blob listed by source (which must support storage.Lister) into
+// destination. Each blob read from source is checked against its address
+// and each blob written to destination is read back and compared with the
+// original before it is counted as copied. Failures to migrate individual
+// blobs are collected in the report rather than aborting the migration.
+func Migrate(source, destination storage.Store, options Options) (*Report, error) {
+	if options.Workers <= 0 {
+		options.Workers = DefaultWorkers
+	}
+	if options.MatchesAddress == nil {
+		options.MatchesAddress = storage.MatchesAddress
+	}
+	checkpointed := map[string]bool{}
+	var checkpoint *checkpointWriter
+	if options.CheckpointFile != "" {
+		var err error
+		checkpointed, err = readCheckpoint(options.CheckpointFile)
+		if err != nil {
+			return nil, err
+		}
+		checkpoint, err = openCheckpoint(options.CheckpointFile)
+		if err != nil {
+			return nil, err
+		}
+		defer checkpoint.Close()
+	}
+
+	report := new(Report)
+	reportMtx := new(sync.Mutex)
+	record := func(address []byte, copied bool, err error) {
+		reportMtx.Lock()
+		defer reportMtx.Unlock()
+		switch {
+		case err != nil:
+			report.Failed = append(report.Failed, &Failure{
+				Address: address,
+				Err:     err,
+			})
+		case copied:
+			report.Copied = append(report.Copied, address)
+		default:
+			report.Skipped = append(report.Skipped, address)
+		}
+	}
+
+	addressCh := make(chan []byte, options.Workers)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for address := range addressCh {
+				copied, err := migrateBlob(source, destination, address,
+					options.MatchesAddress)
+				if err == nil && checkpoint != nil {
+					err = checkpoint.Write(address)
+				}
+				record(address, copied, err)
+			}
+		}()
+	}
+
+	err := storage.List(source, func(address []byte, err error) error {
+		if err != nil {
+			record(nil, false, err)
+			return nil
+		}
+		if checkpointed[string(address)] {
+			record(address, false, nil)
+			return nil
+		}
+		addressCh <- address
+		return nil
+	})
+	close(addressCh)
+	wg.Wait()
+	return report, err
+}
+
+// Returns true if the blob was copied and false if it was already present
+func migrateBlob(source, destination storage.Store, address []byte,
+	matchesAddress func(address, data []byte) bool) (bool, error) {
+
+	statInfo, err := destination.Stat(address)
+	if err != nil {
+		return false, err
+	}
+	if statInfo.Exists {
+		return false, nil
+	}
+	data, err := source.Get(address)
+	if err != nil {
+		return false, err
+	}
+	if !matchesAddress(address, data) {
+		return false, fmt.Errorf("Blob in %s does not match its address",
+			source.Name())
+	}
+	err = destination.Put(address, data)
+	if err != nil {
+		return false, err
+	}
+	written, err := destination.Get(address)
+	if err != nil {
+		return false, err
+	}
+	if sha256.Sum256(written) != sha256.Sum256(data) {
+		return false, fmt.Errorf("Blob read back from %s differs from the "+
+			"blob written to it", destination.Name())
+	}
+	return true, nil
+}
+
+func readCheckpoint(checkpointFile string) (map[string]bool, error) {
+	checkpointed := map[string]bool{}
+	file, err := os.Open(checkpointFile)
+	if os.IsNotExist(err) {
+		return checkpointed, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		address, err := base64.StdEncoding.DecodeString(scanner.Text())
+		// A line may have been torn if a previous migration was interrupted
+		// mid-write, in which case we just migrate that blob again
+		if err != nil {
+			continue
+		}
+		checkpointed[string(address)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Could not read checkpoint file '%s': %s",
+			checkpointFile, err)
+	}
+	return checkpointed, nil
+}
+
+type checkpointWriter struct {
+	sync.Mutex
+	file *os.File
+}
+
+func openCheckpoint(checkpointFile string) (*checkpointWriter, error) {
+	file, err := os.OpenFile(checkpointFile,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open checkpoint file '%s': %s",
+			checkpointFile, err)
+	}
+	return &checkpointWriter{file: file}, nil
+}
+
+func (cw *checkpointWriter) Write(address []byte) error {
+	cw.Lock()
+	defer cw.Unlock()
+	// Start each address on a fresh line in case the file ends with a torn
+	// line from an interrupted migration
+	_, err := fmt.Fprintf(cw.file, "\n%s",
+		base64.StdEncoding.EncodeToString(address))
+	return err
+}
+
+func (cw *checkpointWriter) Close() error {
+	return cw.file.Close()
+}
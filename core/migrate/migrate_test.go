@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/monax/hoard/core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "migrate_test")
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			panic(err)
+		}
+	}()
+	assert.NoError(t, err)
+	checkpointFile := filepath.Join(tempDir, "checkpoint")
+
+	source := storage.NewMemoryStore()
+	destination := storage.NewMemoryStore()
+	addresses := make([][]byte, 20)
+	for i := range addresses {
+		data := []byte{byte(i)}
+		addresses[i] = storage.SHA256Addresser(data)
+		assert.NoError(t, source.Put(addresses[i], data))
+	}
+	// Already present in the destination
+	assert.NoError(t, destination.Put(addresses[0], []byte{0}))
+	// Does not match its address
+	corruptAddress := storage.SHA256Addresser(bs("original"))
+	assert.NoError(t, source.Put(corruptAddress, bs("corrupted")))
+
+	report, err := Migrate(source, destination, Options{
+		Workers:        3,
+		CheckpointFile: checkpointFile,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, report.Copied, 19)
+	assert.Equal(t, [][]byte{addresses[0]}, report.Skipped)
+	if assert.Len(t, report.Failed, 1) {
+		assert.Equal(t, corruptAddress, report.Failed[0].Address)
+	}
+	for i, address := range addresses {
+		data, err := destination.Get(address)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{byte(i)}, data)
+	}
+	statInfo, err := destination.Stat(corruptAddress)
+	assert.NoError(t, err)
+	assert.False(t, statInfo.Exists)
+
+	// Resuming with the checkpoint skips everything migrated without
+	// consulting the destination
+	report, err = Migrate(source, storage.NewMemoryStore(), Options{
+		CheckpointFile: checkpointFile,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Copied)
+	assert.Len(t, report.Skipped, 20)
+	assert.Len(t, report.Failed, 1)
+}
+
+func TestReadCheckpointTornLine(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "migrate_test")
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			panic(err)
+		}
+	}()
+	assert.NoError(t, err)
+	checkpointFile := filepath.Join(tempDir, "checkpoint")
+
+	cw, err := openCheckpoint(checkpointFile)
+	assert.NoError(t, err)
+	assert.NoError(t, cw.Write(bs("first")))
+	_, err = cw.file.WriteString("\nc2Vjb2")
+	assert.NoError(t, err)
+	assert.NoError(t, cw.Write(bs("third")))
+	assert.NoError(t, cw.Close())
+
+	checkpointed, err := readCheckpoint(checkpointFile)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"first": true, "third": true}, checkpointed)
+}
+
+func TestMigrateUnlistableSource(t *testing.T) {
+	blindingStore, err := storage.NewBlindingStore(storage.NewMemoryStore(),
+		bs("a sixteen byte secret"))
+	assert.NoError(t, err)
+	_, err = Migrate(blindingStore, storage.NewMemoryStore(), Options{})
+	assert.Error(t, err)
+}
+
+func bs(s string) []byte {
+	return []byte(s)
+}
@@ -0,0 +1,209 @@
+package reference
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+// The version of the mnemonic encoding, which is the first byte of the encoded
+// reference so that the encoding can change without old mnemonics being misread
+const MnemonicVersion = 1
+
+const (
+	// Each word encodes 11 bits as an index into the 2048 word BIP39 word list
+	bitsPerWord = 11
+	wordCount   = 1 << bitsPerWord
+	// A word of SHA256 of the encoded reference and a word summing the others
+	checksumWords = 2
+)
+
+var mnemonicWords = wordlists.English
+
+var mnemonicIndices = make(map[string]int, len(mnemonicWords))
+
+func init() {
+	for i, word := range mnemonicWords {
+		mnemonicIndices[word] = i
+	}
+}
+
+// Obtain a space-separated list of words from the BIP39 English word list
+// encoding the Ref for transcription onto paper. The reference is serialised
+// compactly and split into 11-bit words as in BIP39, then followed by two
+// checksum words: the first 11 bits of the SHA256 of the serialised reference,
+// and the sum of all the preceding words modulo 2048. The sum guarantees a
+// single mistyped word is detected and the hash catches most other mistakes.
+func (ref *Ref) Mnemonic() string {
+	data := ref.mnemonicBytes()
+	words := make([]int, 0, (len(data)*8+bitsPerWord-1)/bitsPerWord+checksumWords)
+	bits := newBitReader(data)
+	for bits.remaining() > 0 {
+		words = append(words, bits.read(bitsPerWord))
+	}
+	hash := sha256.Sum256(data)
+	words = append(words, newBitReader(hash[:]).read(bitsPerWord))
+	words = append(words, sumWords(words))
+
+	strs := make([]string, len(words))
+	for i, word := range words {
+		strs[i] = mnemonicWords[word]
+	}
+	return strings.Join(strs, " ")
+}
+
+// Read a Ref from a mnemonic as produced by Ref.Mnemonic. Words may be separated
+// by any whitespace and are case-insensitive.
+func FromMnemonic(mnemonic string) (*Ref, error) {
+	strs := strings.Fields(strings.ToLower(mnemonic))
+	if len(strs) <= checksumWords {
+		return nil, fmt.Errorf("Mnemonic has only %v words", len(strs))
+	}
+	words := make([]int, len(strs))
+	for i, str := range strs {
+		word, ok := mnemonicIndices[str]
+		if !ok {
+			return nil, fmt.Errorf("Word %v of mnemonic '%s' is not in the word "+
+				"list", i+1, str)
+		}
+		words[i] = word
+	}
+	last := len(words) - 1
+	if sumWords(words[:last]) != words[last] {
+		return nil, errors.New("Mnemonic checksum does not match, a word may " +
+			"be mistyped, missing, or out of order")
+	}
+	dataWords := words[:last-1]
+
+	// Unpack the 11-bit words into bytes, the final bits are padding
+	data := make([]byte, len(dataWords)*bitsPerWord/8)
+	var acc uint
+	var accBits uint
+	n := 0
+	for _, word := range dataWords {
+		acc = acc<<bitsPerWord | uint(word)
+		accBits += bitsPerWord
+		for accBits >= 8 && n < len(data) {
+			accBits -= 8
+			data[n] = byte(acc >> accBits)
+			acc &= 1<<accBits - 1
+			n++
+		}
+	}
+	if acc != 0 {
+		return nil, errors.New("Mnemonic has non-zero padding bits")
+	}
+
+	ref, length, err := refFromMnemonicBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	// Padding may leave at most one zero byte after the reference
+	if len(dataWords) != (length*8+bitsPerWord-1)/bitsPerWord ||
+		!isZero(data[length:]) {
+		return nil, errors.New("Mnemonic has trailing words after the reference")
+	}
+	hash := sha256.Sum256(data[:length])
+	if newBitReader(hash[:]).read(bitsPerWord) != words[last-1] {
+		return nil, errors.New("Mnemonic hash checksum does not match, a word " +
+			"may be mistyped, missing, or out of order")
+	}
+	return ref, nil
+}
+
+// The version byte followed by length-prefixed fields, so addresses of any
+// length (such as bare SHA256 or multihash addresses) and empty optional
+// fields are encoded unambiguously
+func (ref *Ref) mnemonicBytes() []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(MnemonicVersion)
+	for _, field := range ref.mnemonicFields() {
+		writeLengthPrefixed(buf, field)
+	}
+	return buf.Bytes()
+}
+
+func (ref *Ref) mnemonicFields() [][]byte {
+	return [][]byte{ref.Address, ref.SecretKey, ref.Salt, ref.Context,
+		[]byte(ref.Suite), []byte(ref.Padding)}
+}
+
+// Returns the Ref and the number of bytes of data it was read from
+func refFromMnemonicBytes(data []byte) (*Ref, int, error) {
+	if len(data) == 0 || data[0] != MnemonicVersion {
+		return nil, 0, fmt.Errorf("Mnemonic is not version %v", MnemonicVersion)
+	}
+	fields := make([][]byte, len(new(Ref).mnemonicFields()))
+	n := 1
+	for i := range fields {
+		length, read := binary.Uvarint(data[n:])
+		if read <= 0 || length > uint64(len(data)-n-read) {
+			return nil, 0, errors.New("Mnemonic does not encode a complete reference")
+		}
+		n += read
+		fields[i] = data[n : n+int(length)]
+		n += int(length)
+	}
+	return &Ref{
+		Address:   fields[0],
+		SecretKey: fields[1],
+		Salt:      nilIfEmpty(fields[2]),
+		Context:   nilIfEmpty(fields[3]),
+		Suite:     string(fields[4]),
+		Padding:   string(fields[5]),
+	}, n, nil
+}
+
+func writeLengthPrefixed(buf *bytes.Buffer, field []byte) {
+	prefix := make([]byte, binary.MaxVarintLen64)
+	buf.Write(prefix[:binary.PutUvarint(prefix, uint64(len(field)))])
+	buf.Write(field)
+}
+
+func sumWords(words []int) int {
+	sum := 0
+	for _, word := range words {
+		sum += word
+	}
+	return sum % wordCount
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reads big-endian bit strings from data padding with zero bits past its end
+type bitReader struct {
+	data []byte
+	bit  int
+}
+
+func newBitReader(data []byte) *bitReader {
+	return &bitReader{data: data}
+}
+
+func (br *bitReader) remaining() int {
+	return len(br.data)*8 - br.bit
+}
+
+func (br *bitReader) read(bits int) int {
+	value := 0
+	for i := 0; i < bits; i++ {
+		value <<= 1
+		if br.bit < len(br.data)*8 && br.data[br.bit/8]&(0x80>>uint(br.bit%8)) != 0 {
+			value |= 1
+		}
+		br.bit++
+	}
+	return value
+}
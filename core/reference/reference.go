@@ -2,6 +2,7 @@ package reference
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -9,16 +10,24 @@ type Ref struct {
 	Address   []byte
 	SecretKey []byte
 	Salt      []byte `json:",omitempty"`
+	// The cipher suite the referenced blob was encrypted with, where empty
+	// means the default (AES-256-GCM)
+	Suite string `json:",omitempty"`
+	// The padding scheme applied to the plaintext before encryption, where
+	// empty means none
+	Padding string `json:",omitempty"`
+	// Caller-supplied context authenticated with the blob that must be
+	// presented to decrypt it
+	Context []byte `json:",omitempty"`
 }
 
+// Create a Ref for a blob encrypted with the default suite and no padding or
+// context, set the other fields of Ref for other blobs
 func New(address, secretKey, salt []byte) *Ref {
-	if len(salt) == 0 {
-		salt = nil
-	}
 	return &Ref{
 		Address:   address,
 		SecretKey: secretKey,
-		Salt:      salt,
+		Salt:      nilIfEmpty(salt),
 	}
 }
 
@@ -46,12 +55,23 @@ func (ref *Ref) Plaintext(nonce []byte) string {
 	return string(bs)
 }
 
-func FromPlaintext(plaintext string) *Ref {
+// Read a Ref from its plaintext as produced by Plaintext. The plaintext is not
+// included in errors since it may hold secret keys.
+func FromPlaintext(plaintext string) (*Ref, error) {
 	wrapper := new(refWithNonce)
 	err := json.Unmarshal(([]byte)(plaintext), wrapper)
 	if err != nil {
-		panic(fmt.Errorf("Did not expect an error when deserialising reference, " +
-			"error supressed for security"))
+		return nil, errors.New("Could not deserialise reference from plaintext")
+	}
+	if wrapper.Ref == nil {
+		return nil, errors.New("Reference plaintext does not contain a reference")
+	}
+	return wrapper.Ref, nil
+}
+
+func nilIfEmpty(bs []byte) []byte {
+	if len(bs) == 0 {
+		return nil
 	}
-	return wrapper.Ref
+	return bs
 }
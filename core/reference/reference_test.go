@@ -1,8 +1,10 @@
 package reference
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/monax/hoard/core/encryption"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,10 +44,65 @@ func TestReferencePlaintextDeterministic(t *testing.T) {
 
 func TestReferencePlaintext(t *testing.T) {
 	ref := testReference(nil)
-	assert.Equal(t, ref,
-		FromPlaintext(ref.Plaintext(nil)))
-	assert.Equal(t, ref,
-		FromPlaintext(ref.Plaintext(([]byte)("nonce"))))
+	for _, nonce := range [][]byte{nil, ([]byte)("nonce")} {
+		parsed, err := FromPlaintext(ref.Plaintext(nonce))
+		assert.NoError(t, err)
+		assert.Equal(t, ref, parsed)
+	}
+
+	for _, invalid := range []string{"", "{}", "not json", ref.Plaintext(nil)[:20]} {
+		_, err := FromPlaintext(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestReferenceURI(t *testing.T) {
+	ref := testReference(nil)
+	uri := ref.URI()
+	assert.Equal(t, "hoard:v1:uAQIDBAUGBwEBAgMEBQYHAQECAwQFBgcBAQIDBAUGBwE"+
+		"#uAQIDBAUGBwgBAgMEBQYHCAECAwQFBgcIAQIDBAUGBwg."+uriChecksum(uri[:len(uri)-9]),
+		uri)
+	parsed, err := FromURI(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, ref, parsed)
+
+	ref = &Ref{
+		Address:   ref.Address,
+		SecretKey: ref.SecretKey,
+		Salt:      ([]byte)("salt"),
+		Suite:     "chacha20-poly1305",
+		Padding:   "padme",
+		Context:   ([]byte)("contract address"),
+	}
+	uri = ref.URI()
+	assert.Contains(t, uri, "?context=uY29udHJhY3QgYWRkcmVzcw&padding=padme"+
+		"&salt=uc2FsdA&suite=chacha20-poly1305#")
+	parsed, err = FromURI(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, ref, parsed)
+
+	for _, invalid := range []string{
+		"",
+		"http://example.com",
+		// Truncated
+		uri[:len(uri)-1],
+		uri[:len(uri)-9],
+		uri[:len(uri)-20],
+		uri[:20],
+		// Mistyped
+		strings.Replace(uri, "padme", "padne", 1),
+		// Unsupported version
+		withChecksum(strings.Replace(uri[:len(uri)-9], "v1", "v2", 1)),
+		// No secret key
+		withChecksum(uri[:strings.Index(uri, "#")+1]),
+	} {
+		_, err = FromURI(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func withChecksum(uri string) string {
+	return uri + "." + uriChecksum(uri)
 }
 
 func testReference(salt []byte) *Ref {
@@ -63,3 +120,76 @@ func testReference(salt []byte) *Ref {
 	}
 	return New(address, secretKey, salt)
 }
+
+func TestVerifyCapPlaintext(t *testing.T) {
+	// The default suite does not commit to its key
+	_, err := testReference(nil).VerifyCap()
+	assert.Error(t, err)
+
+	ref := testReference(nil)
+	ref.Suite = encryption.AES256GCMCommittingSuite
+	verifyCap, err := ref.VerifyCap()
+	assert.NoError(t, err)
+	assert.Len(t, verifyCap.KeyCommitment, 32)
+	parsed, err := VerifyCapFromPlaintext(verifyCap.Plaintext())
+	assert.NoError(t, err)
+	assert.Equal(t, verifyCap, parsed)
+
+	// A read capability is not a verify capability
+	_, err = VerifyCapFromPlaintext(ref.Plaintext(nil))
+	assert.Error(t, err)
+	_, err = VerifyCapFromPlaintext("{}")
+	assert.Error(t, err)
+	_, err = VerifyCapFromPlaintext(
+		"{\"Address\":\"AQIDBAUGBwEBAgMEBQYHAQECAwQFBgcBAQIDBAUGBwE=\"}")
+	assert.Error(t, err)
+}
+
+func TestReferenceMnemonic(t *testing.T) {
+	legacy := testReference(nil)
+	multihash := &Ref{
+		Address:   append([]byte{0x12, 0x20}, legacy.Address...),
+		SecretKey: legacy.SecretKey,
+		Salt:      ([]byte)("salt"),
+		Suite:     "chacha20-poly1305",
+		Padding:   "padme",
+		Context:   ([]byte)("contract address"),
+	}
+	for _, ref := range []*Ref{legacy, multihash} {
+		mnemonic := ref.Mnemonic()
+		parsed, err := FromMnemonic(mnemonic)
+		assert.NoError(t, err)
+		assert.Equal(t, ref, parsed)
+
+		parsed, err = FromMnemonic(" " + strings.ToUpper(mnemonic) + "\n")
+		assert.NoError(t, err)
+		assert.Equal(t, ref, parsed)
+
+		// Every single mistyped word is detected
+		words := strings.Fields(mnemonic)
+		for i := range words {
+			for _, typo := range []string{"abandon", "zoo", "ability"} {
+				if words[i] == typo {
+					continue
+				}
+				mistyped := append([]string{}, words...)
+				mistyped[i] = typo
+				_, err = FromMnemonic(strings.Join(mistyped, " "))
+				assert.Error(t, err, "word %v mistyped as %s", i, typo)
+			}
+		}
+		_, err = FromMnemonic(strings.Join(words[:len(words)-1], " "))
+		assert.Error(t, err)
+		_, err = FromMnemonic(strings.Join(append(words, "abandon"), " "))
+		assert.Error(t, err)
+		swapped := append([]string{}, words...)
+		swapped[0], swapped[1] = swapped[1], swapped[0]
+		_, err = FromMnemonic(strings.Join(swapped, " "))
+		assert.Error(t, err)
+	}
+
+	_, err := FromMnemonic("")
+	assert.Error(t, err)
+	_, err = FromMnemonic("not bip39 words")
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package reference
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/monax/hoard/core/storage"
+)
+
+const (
+	URIScheme = "hoard"
+	// The version of the URI format, which follows the scheme so that the
+	// format can change without old URIs being misread
+	URIVersion = "v1"
+	// Bytes of the SHA256 of the rest of the URI appended to it in hex
+	checksumLength = 4
+)
+
+// Bytes within URIs are multibase base64url-encoded so need no escaping
+var uriEncoding, _ = storage.GetAddressEncoding(storage.MultibaseBase64URLEncodingName)
+
+// Obtain a compact, copy-pasteable URI for the Ref of the form:
+//
+//	hoard:v1:<address>?context=<context>&padding=<padding>&salt=<salt>&suite=<suite>#<secret key>.<checksum>
+//
+// where the query parameters are only present when set and the checksum
+// catches truncation or corruption of the URI when it is read by FromURI
+func (ref *Ref) URI() string {
+	query := make(url.Values)
+	if len(ref.Salt) > 0 {
+		query.Set("salt", uriEncoding.EncodeToString(ref.Salt))
+	}
+	if ref.Suite != "" {
+		query.Set("suite", ref.Suite)
+	}
+	if ref.Padding != "" {
+		query.Set("padding", ref.Padding)
+	}
+	if len(ref.Context) > 0 {
+		query.Set("context", uriEncoding.EncodeToString(ref.Context))
+	}
+	u := &url.URL{
+		Scheme:   URIScheme,
+		Opaque:   URIVersion + ":" + uriEncoding.EncodeToString(ref.Address),
+		RawQuery: query.Encode(),
+		Fragment: uriEncoding.EncodeToString(ref.SecretKey),
+	}
+	uri := u.String()
+	return uri + "." + uriChecksum(uri)
+}
+
+// Whether str looks like a URI as produced by Ref.URI (though it may not be
+// valid)
+func IsURI(str string) bool {
+	return strings.HasPrefix(str, URIScheme+":")
+}
+
+// Read a Ref from a URI as produced by Ref.URI
+func FromURI(uri string) (*Ref, error) {
+	if !IsURI(uri) {
+		return nil, fmt.Errorf("Reference URI should begin with '%s:'", URIScheme)
+	}
+	// The checksum follows the secret key in the fragment
+	i := strings.LastIndex(uri, ".")
+	if i < 0 || i < strings.LastIndex(uri, "#") || !strings.Contains(uri, "#") {
+		return nil, errors.New("Reference URI has no checksum, it may be truncated")
+	}
+	if uri[i+1:] != uriChecksum(uri[:i]) {
+		return nil, errors.New("Reference URI checksum does not match, it may " +
+			"be truncated or mistyped")
+	}
+	u, err := url.Parse(uri[:i])
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse reference URI: %v", err)
+	}
+	versionAndAddress := strings.SplitN(u.Opaque, ":", 2)
+	if versionAndAddress[0] != URIVersion {
+		return nil, fmt.Errorf("Reference URI has version '%s' but only '%s' "+
+			"is supported", versionAndAddress[0], URIVersion)
+	}
+	if len(versionAndAddress) < 2 {
+		return nil, errors.New("Reference URI has no address")
+	}
+	address, err := uriEncoding.DecodeString(versionAndAddress[1])
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode reference URI address: %v", err)
+	}
+	secretKey, err := uriEncoding.DecodeString(u.Fragment)
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode reference URI secret key: %v", err)
+	}
+	query := u.Query()
+	var salt, context []byte
+	if query.Get("salt") != "" {
+		salt, err = uriEncoding.DecodeString(query.Get("salt"))
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode reference URI salt: %v", err)
+		}
+	}
+	if query.Get("context") != "" {
+		context, err = uriEncoding.DecodeString(query.Get("context"))
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode reference URI context: %v", err)
+		}
+	}
+	return &Ref{
+		Address:   address,
+		SecretKey: secretKey,
+		Salt:      nilIfEmpty(salt),
+		Suite:     query.Get("suite"),
+		Padding:   query.Get("padding"),
+		Context:   nilIfEmpty(context),
+	}, nil
+}
+
+func uriChecksum(uri string) string {
+	sum := sha256.Sum256([]byte(uri))
+	return hex.EncodeToString(sum[:checksumLength])
+}
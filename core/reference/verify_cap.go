@@ -0,0 +1,92 @@
+package reference
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/monax/hoard/core/encryption"
+	"github.com/monax/hoard/core/storage"
+)
+
+// A verify capability for a blob that can be derived from its Ref (the read
+// capability) and handed to auditors or replication services. It lets its
+// holder fetch the blob and check that it hashes to its address and begins
+// with the key commitment recorded in the cap, but not decrypt it. Only blobs
+// encrypted with a key-committing suite carry a commitment, so only they have
+// verify capabilities.
+//
+// The commitment can be read from the blob by anyone holding its address, so a
+// VerifyCap does not show that whoever made it held the Ref, and verifying it
+// does not show the blob decrypts. It is only as trustworthy as its source.
+type VerifyCap struct {
+	Address []byte
+	// Commitment to the secret key (and so the plaintext hash from which the
+	// secret key was derived) carried by the ciphertext
+	KeyCommitment []byte
+}
+
+func NewVerifyCap(address, keyCommitment []byte) *VerifyCap {
+	return &VerifyCap{
+		Address:       address,
+		KeyCommitment: keyCommitment,
+	}
+}
+
+// Derive the verify capability for the blob referred to by ref. Blobs encrypted
+// with a suite that does not commit to its key have no verify capability since
+// there would be nothing to check beyond their address.
+func (ref *Ref) VerifyCap() (*VerifyCap, error) {
+	suite, err := encryption.GetSuite(ref.Suite)
+	if err != nil {
+		return nil, err
+	}
+	if suite.KeyCommitment == nil {
+		return nil, fmt.Errorf("Cipher suite '%s' does not commit to its key so "+
+			"blobs encrypted with it cannot be verified beyond their address, "+
+			"encrypt with '%s' to obtain a verify capability", suite.Name,
+			encryption.AES256GCMCommittingSuite)
+	}
+	return NewVerifyCap(ref.Address, suite.KeyCommitment(ref.SecretKey)), nil
+}
+
+// Check encryptedData read from the verify cap's address hashes to that address
+// and begins with the verify cap's key commitment (after any header). Nothing
+// else is checked: the authentication tag cannot be checked without the key.
+func (vc *VerifyCap) Verify(encryptedData []byte) error {
+	if !storage.MatchesAddress(vc.Address, encryptedData) {
+		return storage.ErrorAddressCorrupted(vc.Address)
+	}
+	return encryption.VerifyKeyCommitment(vc.KeyCommitment, encryptedData)
+}
+
+// Obtain the canonical plaintext for the VerifyCap. Unlike a Ref it is not
+// secret.
+func (vc *VerifyCap) Plaintext() string {
+	bs, err := json.Marshal(vc)
+	if err != nil {
+		panic(fmt.Errorf("Did not expect an error when serialising verify "+
+			"cap: %v", err))
+	}
+	return string(bs)
+}
+
+// Read a VerifyCap from its plaintext, refusing the plaintext of a Ref so that
+// read capabilities are not accidentally passed around as verify capabilities
+func VerifyCapFromPlaintext(plaintext string) (*VerifyCap, error) {
+	vc := new(VerifyCap)
+	decoder := json.NewDecoder(bytes.NewBufferString(plaintext))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(vc)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read verify cap: %v", err)
+	}
+	if len(vc.Address) == 0 {
+		return nil, errors.New("Verify cap has no address")
+	}
+	if len(vc.KeyCommitment) == 0 {
+		return nil, errors.New("Verify cap has no key commitment")
+	}
+	return NewVerifyCap(vc.Address, vc.KeyCommitment), nil
+}
@@ -5,12 +5,19 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"strings"
 )
 
 const (
 	Base64EncodingName = "base64"
 	Base32EncodingName = "base32"
 	HexEncodingName    = "hex"
+	// Self-describing encodings that prefix encoded addresses with a multibase
+	// code (see https://github.com/multiformats/multibase) and can decode an
+	// address in any of the supported multibase encodings
+	MultibaseBase16EncodingName    = "multibase-base16"
+	MultibaseBase32EncodingName    = "multibase-base32"
+	MultibaseBase64URLEncodingName = "multibase-base64url"
 )
 
 type AddressEncoding interface {
@@ -26,6 +33,12 @@ func GetAddressEncoding(name string) (AddressEncoding, error) {
 		return base32.StdEncoding, nil
 	case HexEncodingName:
 		return NewAddressEncoding(hex.EncodeToString, hex.DecodeString), nil
+	case MultibaseBase16EncodingName:
+		return newMultibaseEncoding('f'), nil
+	case MultibaseBase32EncodingName:
+		return newMultibaseEncoding('b'), nil
+	case MultibaseBase64URLEncodingName:
+		return newMultibaseEncoding('u'), nil
 	}
 	return nil, fmt.Errorf("Could not find an address encoding named '%s'",
 		name)
@@ -53,3 +66,51 @@ func (ae *addressEncoding) EncodeToString(address []byte) string {
 func (ae *addressEncoding) DecodeString(addressString string) ([]byte, error) {
 	return ae.decodeString(addressString)
 }
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Multibase codes for the encodings we can decode
+var multibases = map[byte]struct {
+	encodeToString func([]byte) string
+	decodeString   func(string) ([]byte, error)
+}{
+	'f': {hex.EncodeToString, hex.DecodeString},
+	'F': {
+		func(bs []byte) string { return strings.ToUpper(hex.EncodeToString(bs)) },
+		hex.DecodeString,
+	},
+	'b': {
+		func(bs []byte) string {
+			return strings.ToLower(base32NoPadding.EncodeToString(bs))
+		},
+		func(s string) ([]byte, error) {
+			return base32NoPadding.DecodeString(strings.ToUpper(s))
+		},
+	},
+	'B': {base32NoPadding.EncodeToString, base32NoPadding.DecodeString},
+	'm': {base64.RawStdEncoding.EncodeToString, base64.RawStdEncoding.DecodeString},
+	'M': {base64.StdEncoding.EncodeToString, base64.StdEncoding.DecodeString},
+	'u': {base64.RawURLEncoding.EncodeToString, base64.RawURLEncoding.DecodeString},
+	'U': {base64.URLEncoding.EncodeToString, base64.URLEncoding.DecodeString},
+}
+
+// Encodes with the multibase identified by code and decodes any supported
+// multibase
+func newMultibaseEncoding(code byte) *addressEncoding {
+	return NewAddressEncoding(func(address []byte) string {
+		return string(code) + multibases[code].encodeToString(address)
+	}, DecodeMultibase)
+}
+
+// Decode a string prefixed with the multibase code of its encoding
+func DecodeMultibase(addressString string) ([]byte, error) {
+	if addressString == "" {
+		return nil, fmt.Errorf("Empty string has no multibase code")
+	}
+	multibase, ok := multibases[addressString[0]]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported multibase code '%c' in '%s'",
+			addressString[0], addressString)
+	}
+	return multibase.decodeString(addressString[1:])
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The shortest secret accepted for blinding addresses
+const MinBlindingSecretLength = 16
+
+func ErrorListBlinded(store Store) error {
+	return status.Errorf(codes.Unimplemented, "%s cannot list addresses "+
+		"since blinded keys cannot be mapped back to the addresses they were "+
+		"derived from, so blinded stores cannot be migrated or checked with "+
+		"fsck", store.Name())
+}
+
+type blindingStore struct {
+	store  Store
+	secret []byte
+}
+
+// Decorates store so that data is stored in it under HMAC-SHA256(secret,
+// address) rather than address. Without secret the backend keys cannot be
+// linked to the (publicly computable) addresses of known plaintexts.
+//
+// Since blinding is one-way a blinding store cannot list its addresses, and
+// List returns an ErrorListBlinded explaining so.
+func NewBlindingStore(store Store, secret []byte) (*blindingStore, error) {
+	if len(secret) < MinBlindingSecretLength {
+		return nil, fmt.Errorf("Blinding secret must be at least %v bytes "+
+			"long but is %v bytes long", MinBlindingSecretLength, len(secret))
+	}
+	return &blindingStore{
+		store:  store,
+		secret: secret,
+	}, nil
+}
+
+var _ Store = (*blindingStore)(nil)
+var _ Lister = (*blindingStore)(nil)
+
+func (bls *blindingStore) Get(address []byte) ([]byte, error) {
+	return bls.store.Get(bls.Blind(address))
+}
+
+func (bls *blindingStore) Stat(address []byte) (*StatInfo, error) {
+	return bls.store.Stat(bls.Blind(address))
+}
+
+func (bls *blindingStore) Put(address, data []byte) error {
+	return bls.store.Put(bls.Blind(address), data)
+}
+
+func (bls *blindingStore) Delete(address []byte) error {
+	return Delete(bls.store, bls.Blind(address))
+}
+
+func (bls *blindingStore) List(fn func(address []byte, err error) error) error {
+	return ErrorListBlinded(bls)
+}
+
+func (bls *blindingStore) Location(address []byte) string {
+	return bls.store.Location(bls.Blind(address))
+}
+
+func (bls *blindingStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	return Presign(bls.store, bls.Blind(address), expiry)
+}
+
+func (bls *blindingStore) Snapshot() error {
+	return Snapshot(bls.store)
+}
+
+func (bls *blindingStore) Close() error {
+	return Close(bls.store)
+}
+
+func (bls *blindingStore) Name() string {
+	return fmt.Sprintf("blindingStore<%s>", bls.store.Name())
+}
+
+// The address under which data for address is stored in the underlying store
+func (bls *blindingStore) Blind(address []byte) []byte {
+	mac := hmac.New(sha256.New, bls.secret)
+	mac.Write(address)
+	return mac.Sum(nil)
+}
+
+// Move the data in the unblinded store from which blindingStore was decorated
+// to its blinded address. Each address in the store is copied to its blinded
+// address, and if deleteOriginal is true deleted afterwards. Migration is
+// idempotent so an interrupted migration can be resumed by running it again:
+// entries whose data does not match their address (as checked by
+// MatchesAddress) are taken to be blinded already and skipped, and data is not
+// copied to a blinded address that already exists. Undecodable entries and
+// failures are reported to fn (which can abort the migration by returning an
+// error) along with each migrated address.
+func (bls *blindingStore) Migrate(deleteOriginal bool,
+	fn func(address []byte, err error) error) error {
+
+	var addresses [][]byte
+	err := List(bls.store, func(address []byte, err error) error {
+		if err != nil {
+			return fn(nil, err)
+		}
+		addresses = append(addresses, address)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		migrated, err := bls.migrate(address, deleteOriginal)
+		if !migrated && err == nil {
+			continue
+		}
+		err = fn(address, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns false without an error if address is already blinded
+func (bls *blindingStore) migrate(address []byte, deleteOriginal bool) (bool, error) {
+	data, err := bls.store.Get(address)
+	if err != nil {
+		return false, err
+	}
+	if !MatchesAddress(address, data) {
+		return false, nil
+	}
+	statInfo, err := bls.Stat(address)
+	if err != nil {
+		return false, err
+	}
+	if !statInfo.Exists {
+		err = bls.Put(address, data)
+		if err != nil {
+			return false, err
+		}
+	}
+	if deleteOriginal {
+		return true, Delete(bls.store, address)
+	}
+	return true, nil
+}
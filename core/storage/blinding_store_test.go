@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestBlindingStore(t *testing.T) {
+	_, err := NewBlindingStore(NewMemoryStore(), bs("short"))
+	assert.Error(t, err)
+
+	backend := NewMemoryStore()
+	bls, err := NewBlindingStore(backend, bs("a very secret secret"))
+	assert.NoError(t, err)
+	testStore(t, bls)
+
+	statInfo, err := backend.Stat(bs("address"))
+	assert.NoError(t, err)
+	assert.False(t, statInfo.Exists, "raw address should not be used")
+	data, err := backend.Get(bls.Blind(bs("address")))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("data"), data)
+
+	other, err := NewBlindingStore(backend, bs("another secret secret"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, bls.Blind(bs("address")), other.Blind(bs("address")))
+
+	// Blinded keys cannot be listed as addresses
+	err = List(bls, func(address []byte, err error) error { return nil })
+	assert.Equal(t, codes.Unimplemented, grpc.Code(err))
+	assert.Contains(t, err.Error(), "blinded")
+}
+
+func TestBlindingStoreMigrate(t *testing.T) {
+	backend := NewMemoryStore()
+	foo, bar := SHA256Addresser(bs("foo-data")), SHA256Addresser(bs("bar-data"))
+	assert.NoError(t, backend.Put(foo, bs("foo-data")))
+	assert.NoError(t, backend.Put(bar, bs("bar-data")))
+
+	bls, err := NewBlindingStore(backend, bs("a very secret secret"))
+	assert.NoError(t, err)
+	migrate := func(deleteOriginal bool) [][]byte {
+		var migrated [][]byte
+		err := bls.Migrate(deleteOriginal, func(address []byte, err error) error {
+			assert.NoError(t, err)
+			migrated = append(migrated, address)
+			return nil
+		})
+		assert.NoError(t, err)
+		return migrated
+	}
+	// Copy without deleting (as if interrupted) then resume
+	assert.Len(t, migrate(false), 2)
+	assert.Equal(t, 4, countEntries(t, backend))
+	assert.Len(t, migrate(true), 2)
+
+	data, err := bls.Get(foo)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("foo-data"), data)
+	statInfo, err := backend.Stat(bar)
+	assert.NoError(t, err)
+	assert.False(t, statInfo.Exists)
+	assert.Equal(t, 2, countEntries(t, backend))
+
+	// Running again leaves the blinded entries where they are
+	assert.Len(t, migrate(true), 0)
+	assert.Equal(t, 2, countEntries(t, backend))
+	data, err = bls.Get(bar)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("bar-data"), data)
+}
+
+func countEntries(t *testing.T, store Store) int {
+	var count int
+	assert.NoError(t, List(store, func(address []byte, err error) error {
+		count++
+		return err
+	}))
+	return count
+}
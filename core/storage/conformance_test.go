@@ -0,0 +1,90 @@
+package storage_test
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/storage"
+	"github.com/monax/hoard/core/storage/storagetest"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storagetest.TestStore(t, storage.NewMemoryStore())
+}
+
+func TestSnapshottingMemoryStoreConformance(t *testing.T) {
+	tempDir := tempDir(t)
+	defer os.RemoveAll(tempDir)
+	sms, err := storage.NewSnapshottingMemoryStore(path.Join(tempDir, "snapshot"),
+		time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storagetest.TestStore(t, sms)
+	err = sms.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileSystemStoreConformance(t *testing.T) {
+	for _, addressEncoding := range []storage.AddressEncoding{
+		base64.URLEncoding,
+		base32.StdEncoding,
+	} {
+		tempDir := tempDir(t)
+		defer os.RemoveAll(tempDir)
+		fss, err := storage.NewFileSystemStore(tempDir, addressEncoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		storagetest.TestStore(t, fss)
+	}
+}
+
+func TestDecoratedStoreConformance(t *testing.T) {
+	keyring, err := storage.NewKeyring(1, map[uint32][]byte{
+		1: []byte("0123456789abcdef0123456789abcdef"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blindingStore, err := storage.NewBlindingStore(storage.NewMemoryStore(),
+		[]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := []storage.Store{
+		storage.NewLoggingStore(storage.NewMemoryStore(), log.NewNopLogger()),
+		storage.NewSyncStore(storage.NewMemoryStore()),
+		storage.NewResilientStore(storage.NewMemoryStore(),
+			storage.ResiliencePolicy{
+				MaxAttempts:    2,
+				Timeout:        time.Minute,
+				MaxConcurrency: 4,
+			}, nil),
+		storage.NewEncryptedStore(storage.NewMemoryStore(), keyring),
+		blindingStore,
+		storage.NewFaultStore(storage.NewMemoryStore(), base64.URLEncoding, 1,
+			nil, nil),
+	}
+	for _, store := range stores {
+		t.Run(store.Name(), func(t *testing.T) {
+			storagetest.TestStore(t, store)
+		})
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "storage_conformance_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
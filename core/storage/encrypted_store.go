@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Version of the at-rest encryption header
+const encryptedBlobVersion = 1
+
+const (
+	// Version byte then big-endian key id
+	encryptedHeaderSize = 1 + 4
+	encryptedNonceSize  = 12
+	encryptedTagSize    = 16
+	// The number of bytes by which an at-rest encrypted blob exceeds its
+	// plaintext
+	EncryptedBlobOverhead = encryptedHeaderSize + encryptedNonceSize +
+		encryptedTagSize
+)
+
+// Length of at-rest encryption keys (for AES-256)
+const MasterKeyLength = 32
+
+func ErrorUnknownMasterKey(keyID uint32) error {
+	return status.Errorf(codes.FailedPrecondition, "Blob is encrypted with "+
+		"master key %v which is not in the keyring", keyID)
+}
+
+// A set of master keys indexed by id, one of which is current and used for
+// all new encryption
+type Keyring struct {
+	currentKeyID uint32
+	keys         map[uint32]cipher.AEAD
+}
+
+func NewKeyring(currentKeyID uint32, keys map[uint32][]byte) (*Keyring, error) {
+	kr := &Keyring{
+		currentKeyID: currentKeyID,
+		keys:         make(map[uint32]cipher.AEAD, len(keys)),
+	}
+	for keyID, key := range keys {
+		if len(key) != MasterKeyLength {
+			return nil, fmt.Errorf("Master key %v must be %v bytes long but "+
+				"is %v bytes long", keyID, MasterKeyLength, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		kr.keys[keyID], err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := kr.keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("Current master key %v is not in the keyring",
+			currentKeyID)
+	}
+	return kr, nil
+}
+
+// Read a keyring from its JSON serialisation, of the form:
+//
+//	{"CurrentKeyID": 2, "Keys": {"1": "<base64 key>", "2": "<base64 key>"}}
+func KeyringFromJSON(keyringJSON []byte) (*Keyring, error) {
+	serialised := new(struct {
+		CurrentKeyID uint32
+		Keys         map[string]string
+	})
+	err := json.Unmarshal(keyringJSON, serialised)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse keyring: %s", err)
+	}
+	keys := make(map[uint32][]byte, len(serialised.Keys))
+	for keyIDString, encodedKey := range serialised.Keys {
+		keyID, err := strconv.ParseUint(keyIDString, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse key id '%s' in keyring: %s",
+				keyIDString, err)
+		}
+		keys[uint32(keyID)], err = base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode master key %v in keyring "+
+				"as base64: %s", keyID, err)
+		}
+	}
+	return NewKeyring(serialised.CurrentKeyID, keys)
+}
+
+func (kr *Keyring) CurrentKeyID() uint32 {
+	return kr.currentKeyID
+}
+
+// Encrypt data with a random nonce under the current key, binding it to
+// address
+func (kr *Keyring) Seal(address, data []byte) ([]byte, error) {
+	header := make([]byte, encryptedHeaderSize, EncryptedBlobOverhead+len(data))
+	header[0] = encryptedBlobVersion
+	binary.BigEndian.PutUint32(header[1:], kr.currentKeyID)
+	nonce := header[encryptedHeaderSize : encryptedHeaderSize+encryptedNonceSize]
+	header = header[:encryptedHeaderSize+encryptedNonceSize]
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	aead := kr.keys[kr.currentKeyID]
+	return aead.Seal(header, nonce, data, additionalData(header, address)), nil
+}
+
+// Decrypt a blob produced by Seal for address with any key in the keyring
+func (kr *Keyring) Open(address, blob []byte) ([]byte, error) {
+	keyID, err := BlobKeyID(blob)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := kr.keys[keyID]
+	if !ok {
+		return nil, ErrorUnknownMasterKey(keyID)
+	}
+	header := blob[:encryptedHeaderSize+encryptedNonceSize]
+	data, err := aead.Open(nil, header[encryptedHeaderSize:],
+		blob[len(header):], additionalData(header, address))
+	if err != nil {
+		return nil, status.Errorf(codes.DataLoss, "Could not decrypt at-rest "+
+			"encrypted blob at %s: %s", formatAddress(address), err)
+	}
+	return data, nil
+}
+
+// Get the id of the master key with which blob was encrypted
+func BlobKeyID(blob []byte) (uint32, error) {
+	if len(blob) < EncryptedBlobOverhead {
+		return 0, status.Errorf(codes.DataLoss, "At-rest encrypted blob of "+
+			"%v bytes is too short", len(blob))
+	}
+	if blob[0] != encryptedBlobVersion {
+		return 0, status.Errorf(codes.DataLoss, "At-rest encrypted blob has "+
+			"unknown version %v", blob[0])
+	}
+	return binary.BigEndian.Uint32(blob[1:encryptedHeaderSize]), nil
+}
+
+// Authenticate the header and the address so blobs cannot be swapped between
+// addresses
+func additionalData(header, address []byte) []byte {
+	return append(append([]byte{}, header...), address...)
+}
+
+type encryptedStore struct {
+	store   Store
+	keyring *Keyring
+}
+
+// Decorates store so that data is encrypted (a second time) with a randomised
+// AEAD under a master key from keyring before it reaches the backend. Blobs
+// record the id of their key so keys can be rotated with Rotate while old
+// keys remain in the keyring. Presigned URLs are not supported since they
+// would serve blobs that cannot be decrypted without the keyring.
+func NewEncryptedStore(store Store, keyring *Keyring) *encryptedStore {
+	return &encryptedStore{
+		store:   store,
+		keyring: keyring,
+	}
+}
+
+var _ Store = (*encryptedStore)(nil)
+
+func (es *encryptedStore) Get(address []byte) ([]byte, error) {
+	blob, err := es.store.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	return es.keyring.Open(address, blob)
+}
+
+func (es *encryptedStore) Stat(address []byte) (*StatInfo, error) {
+	statInfo, err := es.store.Stat(address)
+	if err != nil {
+		return nil, err
+	}
+	if statInfo.Exists && statInfo.Size >= EncryptedBlobOverhead {
+		statInfo.Size -= EncryptedBlobOverhead
+	}
+	return statInfo, nil
+}
+
+func (es *encryptedStore) Put(address, data []byte) error {
+	blob, err := es.keyring.Seal(address, data)
+	if err != nil {
+		return err
+	}
+	return es.store.Put(address, blob)
+}
+
+func (es *encryptedStore) Delete(address []byte) error {
+	return Delete(es.store, address)
+}
+
+func (es *encryptedStore) List(fn func(address []byte, err error) error) error {
+	return List(es.store, fn)
+}
+
+func (es *encryptedStore) Location(address []byte) string {
+	return es.store.Location(address)
+}
+
+func (es *encryptedStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	return "", ErrorPresignUnsupported(es)
+}
+
+func (es *encryptedStore) Snapshot() error {
+	return Snapshot(es.store)
+}
+
+func (es *encryptedStore) Close() error {
+	return Close(es.store)
+}
+
+func (es *encryptedStore) Name() string {
+	return fmt.Sprintf("encryptedStore[currentKeyID=%v]<%s>",
+		es.keyring.CurrentKeyID(), es.store.Name())
+}
+
+// Re-encrypt every blob in the underlying store that is not encrypted under
+// the current key with the current key. fn is called with each address
+// re-encrypted or with an error for each blob that could not be (and can
+// abort rotation by returning an error).
+func (es *encryptedStore) Rotate(fn func(address []byte, err error) error) error {
+	return List(es.store, func(address []byte, err error) error {
+		if err != nil {
+			return fn(nil, err)
+		}
+		rotated, err := es.rotate(address)
+		if err != nil || rotated {
+			return fn(address, err)
+		}
+		return nil
+	})
+}
+
+func (es *encryptedStore) rotate(address []byte) (bool, error) {
+	blob, err := es.store.Get(address)
+	if err != nil {
+		return false, err
+	}
+	keyID, err := BlobKeyID(blob)
+	if err != nil {
+		return false, err
+	}
+	if keyID == es.keyring.CurrentKeyID() {
+		return false, nil
+	}
+	data, err := es.keyring.Open(address, blob)
+	if err != nil {
+		return false, err
+	}
+	return true, es.Put(address, data)
+}
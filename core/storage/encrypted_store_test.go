@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	backend := NewMemoryStore()
+	keyring, err := NewKeyring(1, map[uint32][]byte{1: key(1)})
+	assert.NoError(t, err)
+	es := NewEncryptedStore(backend, keyring)
+	testStore(t, es)
+
+	blob, err := backend.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.Len(t, blob, len(bs("data"))+EncryptedBlobOverhead)
+	assert.False(t, bytes.Contains(blob, bs("data")))
+
+	// Randomised
+	assert.NoError(t, es.Put(bs("address"), bs("data")))
+	blob2, err := backend.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, blob, blob2)
+
+	// Bound to address
+	assert.NoError(t, backend.Put(bs("other"), blob))
+	_, err = es.Get(bs("other"))
+	assert.Equal(t, codes.DataLoss, grpc.Code(err))
+
+	_, err = Presign(es, bs("address"), DefaultPresignExpiry)
+	assert.Equal(t, codes.Unimplemented, grpc.Code(err))
+}
+
+func TestEncryptedStoreRotate(t *testing.T) {
+	backend := NewMemoryStore()
+	oldKeyring, err := NewKeyring(1, map[uint32][]byte{1: key(1)})
+	assert.NoError(t, err)
+	assert.NoError(t, NewEncryptedStore(backend, oldKeyring).
+		Put(bs("address"), bs("data")))
+
+	newKeyring, err := NewKeyring(2, map[uint32][]byte{1: key(1), 2: key(2)})
+	assert.NoError(t, err)
+	es := NewEncryptedStore(backend, newKeyring)
+	assert.NoError(t, es.Put(bs("new-address"), bs("new-data")))
+
+	var rotated [][]byte
+	err = es.Rotate(func(address []byte, err error) error {
+		assert.NoError(t, err)
+		rotated = append(rotated, address)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{bs("address")}, rotated)
+
+	blob, err := backend.Get(bs("address"))
+	assert.NoError(t, err)
+	keyID, err := BlobKeyID(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), keyID)
+
+	// Old key no longer needed
+	rotatedKeyring, err := NewKeyring(2, map[uint32][]byte{2: key(2)})
+	assert.NoError(t, err)
+	data, err := NewEncryptedStore(backend, rotatedKeyring).Get(bs("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("data"), data)
+
+	_, err = NewEncryptedStore(backend, oldKeyring).Get(bs("address"))
+	assert.Equal(t, codes.FailedPrecondition, grpc.Code(err))
+}
+
+func TestKeyringFromJSON(t *testing.T) {
+	keyring, err := KeyringFromJSON(bs(`{"CurrentKeyID": 2, "Keys": {
+		"1": "MTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTE=",
+		"2": "MjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjI="}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), keyring.CurrentKeyID())
+
+	_, err = KeyringFromJSON(bs(`{"CurrentKeyID": 3, "Keys": {
+		"1": "MTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTE="}}`))
+	assert.Error(t, err)
+
+	_, err = KeyringFromJSON(bs(`{"CurrentKeyID": 1, "Keys": {"1": "c2hvcnQ="}}`))
+	assert.Error(t, err)
+}
+
+func key(b byte) []byte {
+	return bytes.Repeat([]byte{b}, MasterKeyLength)
+}
@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func ErrorInjectedFault(method string, address []byte) error {
+	return status.Errorf(codes.Unavailable, "Injected fault in %s of %s",
+		method, formatAddress(address))
+}
+
+// Distributions from which injected latencies are drawn
+const (
+	// Uniformly between MinLatency and MaxLatency (or exactly MinLatency if
+	// MaxLatency is not greater)
+	UniformLatency = "uniform"
+	// MinLatency plus an exponentially distributed delay with mean MeanLatency
+	// capped at MaxLatency (if set), modelling a long tail of slow calls
+	ExponentialLatency = "exponential"
+)
+
+// Describes faults to inject into calls to a store. Rates are probabilities
+// between 0 and 1 evaluated independently on each call.
+type FaultRule struct {
+	// Only calls for addresses whose encoded form matches are affected, nil
+	// matches all addresses
+	AddressPattern *regexp.Regexp
+	// Only calls to these methods (of Get, Stat, Put, and Delete) are affected,
+	// empty matches all methods
+	Methods []string
+	// Rate at which calls fail without reaching the underlying store
+	ErrorRate float64
+	// Latency added to each call drawn from LatencyDistribution, which
+	// defaults to UniformLatency
+	LatencyDistribution string
+	MinLatency          time.Duration
+	MaxLatency          time.Duration
+	// The mean of the delay added to MinLatency by ExponentialLatency
+	MeanLatency time.Duration
+	// Rate at which Get returns only a prefix of the stored data
+	TruncateRate float64
+	// Rate at which Get returns the stored data with a single bit flipped
+	BitFlipRate float64
+}
+
+func (fr *FaultRule) matches(method, encodedAddress string) bool {
+	if fr.AddressPattern != nil && !fr.AddressPattern.MatchString(encodedAddress) {
+		return false
+	}
+	if len(fr.Methods) == 0 {
+		return true
+	}
+	for _, m := range fr.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+type faultStore struct {
+	store           Store
+	addressEncoding AddressEncoding
+	rules           []*FaultRule
+	seed            int64
+	mtx             sync.Mutex
+	random          *rand.Rand
+	logger          log.Logger
+}
+
+// Decorates store to inject faults into calls according to the first of rules
+// that matches each call, for testing applications against a misbehaving
+// store. Addresses are encoded with addressEncoding to be matched against rule
+// address patterns. Faults are drawn from a source seeded with seed so a
+// sequence of calls made in the same order sees the same faults.
+func NewFaultStore(store Store, addressEncoding AddressEncoding, seed int64,
+	rules []*FaultRule, logger log.Logger) *faultStore {
+
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	fs := &faultStore{
+		store:           store,
+		addressEncoding: addressEncoding,
+		rules:           rules,
+		seed:            seed,
+		random:          rand.New(rand.NewSource(seed)),
+	}
+	fs.logger = logging.TraceLogger(log.With(logger, "module", "storage",
+		"store", fs.Name()))
+	return fs
+}
+
+var _ Store = (*faultStore)(nil)
+
+func (fs *faultStore) Get(address []byte) ([]byte, error) {
+	rule, err := fs.inject("Get", address)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.store.Get(address)
+	if err != nil || rule == nil || len(data) == 0 {
+		return data, err
+	}
+	if fs.chance(rule.TruncateRate) {
+		length := fs.intn(len(data))
+		fs.logger.Log("fault", "truncate", "address", formatAddress(address),
+			"length", length)
+		return data[:length], nil
+	}
+	if fs.chance(rule.BitFlipRate) {
+		bit := fs.intn(len(data) * 8)
+		fs.logger.Log("fault", "bit_flip", "address", formatAddress(address),
+			"bit", bit)
+		// Do not modify the underlying store's copy
+		flipped := make([]byte, len(data))
+		copy(flipped, data)
+		flipped[bit/8] ^= 1 << uint(bit%8)
+		return flipped, nil
+	}
+	return data, nil
+}
+
+func (fs *faultStore) Stat(address []byte) (*StatInfo, error) {
+	_, err := fs.inject("Stat", address)
+	if err != nil {
+		return nil, err
+	}
+	return fs.store.Stat(address)
+}
+
+func (fs *faultStore) Put(address, data []byte) error {
+	_, err := fs.inject("Put", address)
+	if err != nil {
+		return err
+	}
+	return fs.store.Put(address, data)
+}
+
+func (fs *faultStore) Delete(address []byte) error {
+	_, err := fs.inject("Delete", address)
+	if err != nil {
+		return err
+	}
+	return Delete(fs.store, address)
+}
+
+func (fs *faultStore) List(fn func(address []byte, err error) error) error {
+	return List(fs.store, fn)
+}
+
+func (fs *faultStore) Location(address []byte) string {
+	return fs.store.Location(address)
+}
+
+func (fs *faultStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	return Presign(fs.store, address, expiry)
+}
+
+func (fs *faultStore) Snapshot() error {
+	return Snapshot(fs.store)
+}
+
+func (fs *faultStore) Close() error {
+	return Close(fs.store)
+}
+
+func (fs *faultStore) Name() string {
+	return fmt.Sprintf("faultStore[seed=%v,rules=%v]<%s>", fs.seed,
+		len(fs.rules), fs.store.Name())
+}
+
+// Find the rule matching a call, apply any latency, and return an error if the
+// call should fail
+func (fs *faultStore) inject(method string, address []byte) (*FaultRule, error) {
+	rule := fs.rule(method, address)
+	if rule == nil {
+		return nil, nil
+	}
+	latency := fs.latency(rule)
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if fs.chance(rule.ErrorRate) {
+		fs.logger.Log("fault", "error", "method", method,
+			"address", formatAddress(address))
+		return rule, ErrorInjectedFault(method, address)
+	}
+	return rule, nil
+}
+
+func (fs *faultStore) latency(rule *FaultRule) time.Duration {
+	latency := rule.MinLatency
+	switch rule.LatencyDistribution {
+	case ExponentialLatency:
+		if rule.MeanLatency > 0 {
+			latency += time.Duration(fs.expFloat64() * float64(rule.MeanLatency))
+		}
+		if rule.MaxLatency > 0 && latency > rule.MaxLatency {
+			latency = rule.MaxLatency
+		}
+	default:
+		if rule.MaxLatency > rule.MinLatency {
+			latency += time.Duration(fs.int63n(int64(rule.MaxLatency - rule.MinLatency)))
+		}
+	}
+	return latency
+}
+
+func (fs *faultStore) rule(method string, address []byte) *FaultRule {
+	encodedAddress := fs.addressEncoding.EncodeToString(address)
+	for _, rule := range fs.rules {
+		if rule.matches(method, encodedAddress) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (fs *faultStore) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	return fs.random.Float64() < rate
+}
+
+func (fs *faultStore) intn(n int) int {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	return fs.random.Intn(n)
+}
+
+func (fs *faultStore) int63n(n int64) int64 {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	return fs.random.Int63n(n)
+}
+
+func (fs *faultStore) expFloat64() float64 {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	return fs.random.ExpFloat64()
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestFaultStoreNoRules(t *testing.T) {
+	testStore(t, NewFaultStore(NewMemoryStore(), base64.URLEncoding, 1, nil, nil))
+}
+
+func TestFaultStoreErrors(t *testing.T) {
+	fs := NewFaultStore(NewMemoryStore(), base64.URLEncoding, 1,
+		[]*FaultRule{
+			{
+				AddressPattern: regexp.MustCompile("^" +
+					base64.URLEncoding.EncodeToString(bs("bad"))),
+				ErrorRate: 1,
+			},
+		}, nil)
+	assert.NoError(t, fs.Put(bs("good"), bs("data")))
+	err := fs.Put(bs("bad-address"), bs("data"))
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	_, err = fs.Stat(bs("bad-address"))
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+}
+
+func TestFaultStoreReads(t *testing.T) {
+	newFaultStore := func(rule *FaultRule) Store {
+		store := NewMemoryStore()
+		store.Put(bs("address"), bs("some data"))
+		return NewFaultStore(store, base64.URLEncoding, 42,
+			[]*FaultRule{rule}, nil)
+	}
+
+	fs := newFaultStore(&FaultRule{Methods: []string{"Get"}, TruncateRate: 1})
+	data, err := fs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.True(t, len(data) < len(bs("some data")))
+
+	fs = newFaultStore(&FaultRule{BitFlipRate: 1})
+	data, err = fs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.Len(t, data, len(bs("some data")))
+	assert.NotEqual(t, bs("some data"), data)
+
+	fs = newFaultStore(&FaultRule{Methods: []string{"Put"}, BitFlipRate: 1})
+	data, err = fs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("some data"), data)
+
+	fs = newFaultStore(&FaultRule{
+		MinLatency: 10 * time.Millisecond,
+		MaxLatency: 20 * time.Millisecond,
+	})
+	start := time.Now()
+	_, err = fs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 10*time.Millisecond)
+
+	// Minimum latency on its own
+	fs = newFaultStore(&FaultRule{MinLatency: 10 * time.Millisecond})
+	start = time.Now()
+	_, err = fs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 10*time.Millisecond)
+}
+
+func TestFaultStoreLatencyDistributions(t *testing.T) {
+	fs := NewFaultStore(NewMemoryStore(), base64.URLEncoding, 7, nil, nil)
+	uniform := &FaultRule{
+		MinLatency: 10 * time.Millisecond,
+		MaxLatency: 20 * time.Millisecond,
+	}
+	exponential := &FaultRule{
+		LatencyDistribution: ExponentialLatency,
+		MinLatency:          10 * time.Millisecond,
+		MeanLatency:         5 * time.Millisecond,
+		MaxLatency:          40 * time.Millisecond,
+	}
+	var total time.Duration
+	for i := 0; i < 1000; i++ {
+		latency := fs.latency(uniform)
+		assert.True(t, latency >= uniform.MinLatency && latency < uniform.MaxLatency)
+		latency = fs.latency(exponential)
+		assert.True(t, latency >= exponential.MinLatency &&
+			latency <= exponential.MaxLatency)
+		total += latency
+	}
+	mean := total / 1000
+	assert.True(t, mean > 13*time.Millisecond && mean < 17*time.Millisecond,
+		"mean exponential latency %v", mean)
+}
+
+func TestFaultStoreDeterministic(t *testing.T) {
+	faults := func() []bool {
+		fs := NewFaultStore(NewMemoryStore(), base64.URLEncoding, 7,
+			[]*FaultRule{{ErrorRate: 0.5}}, nil)
+		var failed []bool
+		for i := 0; i < 32; i++ {
+			failed = append(failed, fs.Put(bs("address"), bs("data")) != nil)
+		}
+		return failed
+	}
+	assert.Equal(t, faults(), faults())
+}
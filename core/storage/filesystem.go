@@ -30,7 +30,11 @@ func (fss *fileSystemStore) Put(address, data []byte) error {
 }
 
 func (fss *fileSystemStore) Get(address []byte) ([]byte, error) {
-	return ioutil.ReadFile(fss.Path(address))
+	data, err := ioutil.ReadFile(fss.Path(address))
+	if os.IsNotExist(err) {
+		return nil, ErrorAddressNotFound(address)
+	}
+	return data, err
 }
 
 func (fss *fileSystemStore) Stat(address []byte) (*StatInfo, error) {
@@ -48,6 +52,37 @@ func (fss *fileSystemStore) Stat(address []byte) (*StatInfo, error) {
 	return statInfo, err
 }
 
+func (fss *fileSystemStore) Delete(address []byte) error {
+	err := os.Remove(fss.Path(address))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (fss *fileSystemStore) List(fn func(address []byte, err error) error) error {
+	fileInfos, err := ioutil.ReadDir(fss.rootDirectory)
+	if err != nil {
+		return err
+	}
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() {
+			continue
+		}
+		address, err := fss.addressEncoding.DecodeString(fileInfo.Name())
+		if err != nil {
+			err = fn(nil, fmt.Errorf("Could not decode file name '%s' as an "+
+				"address: %s", fileInfo.Name(), err))
+		} else {
+			err = fn(address, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (fss *fileSystemStore) Location(address []byte) string {
 	filePath := fss.Path(address)
 	uri, err := url.Parse(filePath)
@@ -24,4 +24,19 @@ func TestFileSystemStore(t *testing.T) {
 
 	assert.NoError(t, err)
 	testStore(t, fss)
+
+	assert.NoError(t, ioutil.WriteFile(tempDir+"/not an address", nil, 0644))
+	var addresses [][]byte
+	var errs []error
+	err = List(fss, func(address []byte, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			addresses = append(addresses, address)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, addresses, 2)
+	assert.Len(t, errs, 1)
 }
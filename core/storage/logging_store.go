@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/base64"
+	"time"
 
 	"fmt"
 
@@ -41,11 +42,28 @@ func (ls *loggingStore) Stat(address []byte) (*StatInfo, error) {
 	return ls.store.Stat(address)
 }
 
+func (ls *loggingStore) List(fn func(address []byte, err error) error) error {
+	ls.logger.Log("method", "List")
+	return List(ls.store, fn)
+}
+
 func (ls *loggingStore) Location(address []byte) string {
 	ls.logger.Log("method", "Location", "address", formatAddress(address))
 	return ls.store.Location(address)
 }
 
+func (ls *loggingStore) Delete(address []byte) error {
+	ls.logger.Log("method", "Delete", "address", formatAddress(address))
+	return Delete(ls.store, address)
+}
+
+func (ls *loggingStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	ls.logger.Log("method", "Presign", "address", formatAddress(address),
+		"expiry", expiry)
+	return Presign(ls.store, address, expiry)
+}
+
 func (ls *loggingStore) Name() string {
 	return fmt.Sprintf("loggingStore<%s>", ls.store.Name())
 }
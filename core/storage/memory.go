@@ -11,6 +11,10 @@ type memoryStore struct {
 }
 
 func NewMemoryStore() Store {
+	return newMemoryStore()
+}
+
+func newMemoryStore() *memoryStore {
 	return &memoryStore{
 		memory: make(map[string][]byte),
 		mtx:    new(sync.RWMutex),
@@ -40,10 +44,33 @@ func (ms *memoryStore) Stat(address []byte) (*StatInfo, error) {
 	}, nil
 }
 
+func (ms *memoryStore) Delete(address []byte) error {
+	ms.mtx.Lock()
+	delete(ms.memory, string(address))
+	ms.mtx.Unlock()
+	return nil
+}
+
 func (ms *memoryStore) Location(address []byte) string {
 	return fmt.Sprintf("memfs://%x", address)
 }
 
+func (ms *memoryStore) List(fn func(address []byte, err error) error) error {
+	ms.mtx.RLock()
+	addresses := make([][]byte, 0, len(ms.memory))
+	for address := range ms.memory {
+		addresses = append(addresses, []byte(address))
+	}
+	ms.mtx.RUnlock()
+	for _, address := range addresses {
+		err := fn(address, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (ms *memoryStore) Name() string {
 	return "memoryStore"
 }
@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/logging"
+)
+
+// Memory snapshots are laid out as:
+//
+//	magic (8 bytes) | version (1 byte) | entry count (uvarint) |
+//	entries of: address length (uvarint) | address | data length (uvarint) | data |
+//	SHA256 of all preceding bytes (32 bytes)
+var memorySnapshotMagic = []byte("HOARDMEM")
+
+const memorySnapshotVersion = 1
+
+// Write the contents of the store to w in the snapshot format
+func (ms *memoryStore) WriteSnapshot(w io.Writer) error {
+	ms.mtx.RLock()
+	defer ms.mtx.RUnlock()
+	digest := sha256.New()
+	bw := bufio.NewWriter(io.MultiWriter(w, digest))
+	bw.Write(memorySnapshotMagic)
+	bw.WriteByte(memorySnapshotVersion)
+	writeUvarint(bw, uint64(len(ms.memory)))
+	for address, data := range ms.memory {
+		writeUvarint(bw, uint64(len(address)))
+		bw.WriteString(address)
+		writeUvarint(bw, uint64(len(data)))
+		bw.Write(data)
+	}
+	err := bw.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(digest.Sum(nil))
+	return err
+}
+
+// Replace the contents of the store with those of a snapshot read from r
+func (ms *memoryStore) ReadSnapshot(r io.Reader) error {
+	digest := sha256.New()
+	br := &digestingReader{
+		reader: bufio.NewReader(r),
+		digest: digest,
+	}
+	magic := make([]byte, len(memorySnapshotMagic))
+	_, err := io.ReadFull(br, magic)
+	if err != nil {
+		return fmt.Errorf("Could not read memory snapshot header: %s", err)
+	}
+	if !bytes.Equal(magic, memorySnapshotMagic) {
+		return errors.New("File is not a Hoard memory snapshot")
+	}
+	version, err := br.ReadByte()
+	if err != nil {
+		return err
+	}
+	if version != memorySnapshotVersion {
+		return fmt.Errorf("Memory snapshot has version %v but only version %v "+
+			"is supported", version, memorySnapshotVersion)
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return err
+	}
+	memory := make(map[string][]byte)
+	for i := uint64(0); i < count; i++ {
+		address, err := readLengthPrefixed(br)
+		if err != nil {
+			return fmt.Errorf("Could not read memory snapshot entry: %s", err)
+		}
+		data, err := readLengthPrefixed(br)
+		if err != nil {
+			return fmt.Errorf("Could not read memory snapshot entry: %s", err)
+		}
+		memory[string(address)] = data
+	}
+	expectedSum := digest.Sum(nil)
+	sum := make([]byte, sha256.Size)
+	_, err = io.ReadFull(br.reader, sum)
+	if err != nil {
+		return fmt.Errorf("Could not read memory snapshot checksum: %s", err)
+	}
+	if !bytes.Equal(sum, expectedSum) {
+		return errors.New("Memory snapshot checksum does not match its " +
+			"contents so it is corrupted")
+	}
+	ms.mtx.Lock()
+	ms.memory = memory
+	ms.mtx.Unlock()
+	return nil
+}
+
+type snapshottingMemoryStore struct {
+	*memoryStore
+	snapshotFile string
+	interval     time.Duration
+	// Serialises snapshots
+	snapshotMtx sync.Mutex
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+	logger      log.Logger
+}
+
+// Create a memory store that is loaded from snapshotFile if it exists and
+// written back to it when Close or Snapshot is called and, if interval is
+// non-zero, every interval in the background.
+func NewSnapshottingMemoryStore(snapshotFile string, interval time.Duration,
+	logger log.Logger) (*snapshottingMemoryStore, error) {
+
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	sms := &snapshottingMemoryStore{
+		memoryStore:  newMemoryStore(),
+		snapshotFile: snapshotFile,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+	}
+	sms.logger = log.With(logger, "module", "storage", "store", sms.Name())
+
+	file, err := os.Open(snapshotFile)
+	if err == nil {
+		err = sms.ReadSnapshot(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Could not load memory snapshot '%s': %s",
+				snapshotFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go sms.snapshotEvery(interval)
+	}
+	return sms, nil
+}
+
+var _ Snapshotter = (*snapshottingMemoryStore)(nil)
+
+// Write the store to its snapshot file atomically
+func (sms *snapshottingMemoryStore) Snapshot() error {
+	sms.snapshotMtx.Lock()
+	defer sms.snapshotMtx.Unlock()
+	file, err := ioutil.TempFile(filepath.Dir(sms.snapshotFile),
+		filepath.Base(sms.snapshotFile)+".tmp")
+	if err != nil {
+		return err
+	}
+	err = sms.WriteSnapshot(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), sms.snapshotFile)
+}
+
+// Stop any background snapshots and write a final snapshot. Can be called
+// more than once.
+func (sms *snapshottingMemoryStore) Close() error {
+	sms.stopOnce.Do(func() {
+		close(sms.stopCh)
+	})
+	return sms.Snapshot()
+}
+
+func (sms *snapshottingMemoryStore) Name() string {
+	return fmt.Sprintf("memoryStore[snapshotFile=%s,interval=%s]",
+		sms.snapshotFile, sms.interval)
+}
+
+func (sms *snapshottingMemoryStore) snapshotEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := sms.Snapshot()
+			if err != nil {
+				logging.InfoMsg(sms.logger, "Could not write memory snapshot",
+					"error", err)
+			}
+		case <-sms.stopCh:
+			return
+		}
+	}
+}
+
+// Reads through reader feeding bytes read to digest
+type digestingReader struct {
+	reader *bufio.Reader
+	digest hash.Hash
+}
+
+func (dr *digestingReader) Read(p []byte) (int, error) {
+	n, err := dr.reader.Read(p)
+	dr.digest.Write(p[:n])
+	return n, err
+}
+
+func (dr *digestingReader) ReadByte() (byte, error) {
+	b, err := dr.reader.ReadByte()
+	if err == nil {
+		dr.digest.Write([]byte{b})
+	}
+	return b, err
+}
+
+func writeUvarint(w io.Writer, x uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	w.Write(buf[:binary.PutUvarint(buf, x)])
+}
+
+func readLengthPrefixed(br *digestingReader) ([]byte, error) {
+	length, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if int64(length) < 0 {
+		return nil, fmt.Errorf("length %v is too large", length)
+	}
+	// Read incrementally so that a corrupted length cannot cause a huge
+	// allocation
+	buf := new(bytes.Buffer)
+	n, err := io.CopyN(buf, br, int64(length))
+	if err != nil {
+		return nil, fmt.Errorf("expected %v bytes but read %v: %s", length, n,
+			err)
+	}
+	return buf.Bytes(), nil
+}
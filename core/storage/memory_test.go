@@ -1,7 +1,72 @@
 package storage
 
-import "testing"
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
 
 func TestMemoryStore(t *testing.T) {
 	testStore(t, NewMemoryStore())
 }
+
+func TestMemoryStorePresign(t *testing.T) {
+	_, err := Presign(NewLoggingStore(NewSyncStore(NewMemoryStore()),
+		log.NewNopLogger()),
+		bs("address"), time.Minute)
+	assert.Equal(t, codes.Unimplemented, grpc.Code(err))
+}
+
+func TestMemoryStoreSnapshot(t *testing.T) {
+	ms := newMemoryStore()
+	testStore(t, ms)
+	buf := new(bytes.Buffer)
+	assert.NoError(t, ms.WriteSnapshot(buf))
+	snapshot := buf.Bytes()
+
+	restored := newMemoryStore()
+	assert.NoError(t, restored.ReadSnapshot(bytes.NewReader(snapshot)))
+	assert.Equal(t, ms.memory, restored.memory)
+
+	corrupted := append([]byte{}, snapshot...)
+	corrupted[len(memorySnapshotMagic)+3] ^= 1
+	assert.Error(t, newMemoryStore().ReadSnapshot(bytes.NewReader(corrupted)))
+	assert.Error(t, newMemoryStore().ReadSnapshot(
+		bytes.NewReader(snapshot[:len(snapshot)-1])))
+	assert.Error(t, newMemoryStore().ReadSnapshot(bytes.NewReader(bs("garbage"))))
+}
+
+func TestSnapshottingMemoryStore(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "memory_test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	snapshotFile := path.Join(tempDir, "snapshot")
+
+	sms, err := NewSnapshottingMemoryStore(snapshotFile, time.Millisecond, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, sms.Put(bs("address"), bs("data")))
+	time.Sleep(20 * time.Millisecond)
+	// Written in background
+	_, err = os.Stat(snapshotFile)
+	assert.NoError(t, err)
+	assert.NoError(t, sms.Put(bs("address2"), bs("data2")))
+	assert.NoError(t, Close(sms))
+	// Closing again is harmless
+	assert.NoError(t, Close(sms))
+
+	sms, err = NewSnapshottingMemoryStore(snapshotFile, 0, nil)
+	assert.NoError(t, err)
+	data, err := sms.Get(bs("address2"))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("data2"), data)
+	assert.NoError(t, Snapshot(sms))
+	assert.Equal(t, codes.Unimplemented, grpc.Code(Snapshot(NewMemoryStore())))
+}
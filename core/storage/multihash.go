@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// Names of the hash algorithms that can be used to derive addresses
+const (
+	SHA2_256HashName   = "sha2-256"
+	SHA3_256HashName   = "sha3-256"
+	BLAKE2b256HashName = "blake2b-256"
+	DefaultHashName    = SHA2_256HashName
+)
+
+// Addresses from before multihashes were introduced are bare SHA256 digests
+const legacyAddressLength = sha256.Size
+
+// A hash function identified by its multihash code
+// (see https://github.com/multiformats/multicodec/blob/master/table.csv)
+type HashAlgorithm struct {
+	Name string
+	Code uint64
+	Size int
+	New  func() hash.Hash
+}
+
+var hashAlgorithms = []*HashAlgorithm{
+	{
+		Name: SHA2_256HashName,
+		Code: 0x12,
+		Size: sha256.Size,
+		New:  sha256.New,
+	},
+	{
+		Name: SHA3_256HashName,
+		Code: 0x16,
+		Size: 32,
+		New:  sha3.New256,
+	},
+	{
+		Name: BLAKE2b256HashName,
+		Code: 0xb220,
+		Size: blake2b.Size256,
+		New: func() hash.Hash {
+			// Only fails for keys that are too long
+			hasher, _ := blake2b.New256(nil)
+			return hasher
+		},
+	},
+}
+
+func GetHashAlgorithm(name string) (*HashAlgorithm, error) {
+	if name == "" {
+		name = DefaultHashName
+	}
+	for _, ha := range hashAlgorithms {
+		if ha.Name == name {
+			return ha, nil
+		}
+	}
+	return nil, fmt.Errorf("Could not find a hash algorithm named '%s'", name)
+}
+
+func GetHashAlgorithmByCode(code uint64) (*HashAlgorithm, error) {
+	for _, ha := range hashAlgorithms {
+		if ha.Code == code {
+			return ha, nil
+		}
+	}
+	return nil, fmt.Errorf("Could not find a hash algorithm with multihash "+
+		"code 0x%x", code)
+}
+
+// Get the hash of data as a multihash: the uvarint algorithm code, the uvarint
+// digest length, then the digest
+func (ha *HashAlgorithm) Multihash(data []byte) []byte {
+	hasher := ha.New()
+	hasher.Write(data)
+	prefix := make([]byte, 2*binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, ha.Code)
+	n += binary.PutUvarint(prefix[n:], uint64(ha.Size))
+	return hasher.Sum(prefix[:n])
+}
+
+// Derives addresses as multihashes with this algorithm
+func (ha *HashAlgorithm) Addresser() func(data []byte) []byte {
+	return ha.Multihash
+}
+
+// Split a multihash into the algorithm that produced it and its digest
+func DecodeMultihash(multihash []byte) (*HashAlgorithm, []byte, error) {
+	code, n := binary.Uvarint(multihash)
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("Could not read multihash code")
+	}
+	length, m := binary.Uvarint(multihash[n:])
+	if m <= 0 {
+		return nil, nil, fmt.Errorf("Could not read multihash digest length")
+	}
+	digest := multihash[n+m:]
+	if uint64(len(digest)) != length {
+		return nil, nil, fmt.Errorf("Multihash declares a %v byte digest but "+
+			"has %v bytes", length, len(digest))
+	}
+	ha, err := GetHashAlgorithmByCode(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(digest) != ha.Size {
+		return nil, nil, fmt.Errorf("Multihash has a %v byte %s digest but "+
+			"expected %v bytes", len(digest), ha.Name, ha.Size)
+	}
+	return ha, digest, nil
+}
+
+// Check that data hashes to address, where address is either a multihash
+// with any supported algorithm or a bare SHA256 digest as used for addresses
+// before multihashes were introduced
+func MatchesAddress(address, data []byte) bool {
+	ha, _, err := DecodeMultihash(address)
+	if err == nil {
+		return bytes.Equal(ha.Multihash(data), address)
+	}
+	if len(address) == legacyAddressLength {
+		return bytes.Equal(SHA256Addresser(data), address)
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultihash(t *testing.T) {
+	// Known multihashes of "foo"
+	for name, expected := range map[string]string{
+		SHA2_256HashName: "1220" +
+			"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+		SHA3_256HashName: "1620" +
+			"76d3bc41c9f588f7fcd0d5bf4718f8f84b1c41b20882703100b9eb9413807c01",
+		BLAKE2b256HashName: "a0e40220" +
+			"b8fe9f7f6255a6fa08f668ab632a8d081ad87983c77cd274e48ce450f0b349fd",
+	} {
+		ha, err := GetHashAlgorithm(name)
+		if assert.NoError(t, err) {
+			multihash := ha.Multihash(bs("foo"))
+			assert.Equal(t, expected, hex.EncodeToString(multihash), name)
+			decoded, digest, err := DecodeMultihash(multihash)
+			assert.NoError(t, err)
+			assert.Equal(t, ha, decoded)
+			assert.Len(t, digest, 32)
+			assert.True(t, MatchesAddress(multihash, bs("foo")))
+			assert.False(t, MatchesAddress(multihash, bs("bar")))
+		}
+	}
+
+	_, err := GetHashAlgorithm("md5")
+	assert.Error(t, err)
+	ha, err := GetHashAlgorithm("")
+	assert.NoError(t, err)
+	assert.Equal(t, SHA2_256HashName, ha.Name)
+}
+
+func TestDecodeMultihashInvalid(t *testing.T) {
+	sha2, err := GetHashAlgorithm(SHA2_256HashName)
+	assert.NoError(t, err)
+	multihash := sha2.Multihash(bs("foo"))
+	for _, invalid := range [][]byte{
+		nil,
+		{0x12},
+		multihash[:len(multihash)-1],
+		append(multihash, 0),
+		// Unknown code
+		append([]byte{0x13}, multihash[1:]...),
+		// Digest too short for its algorithm
+		{0x12, 0x01, 0xff},
+	} {
+		_, _, err := DecodeMultihash(invalid)
+		assert.Error(t, err, "%x", invalid)
+	}
+}
+
+func TestMatchesLegacyAddress(t *testing.T) {
+	legacyAddress := SHA256Addresser(bs("foo"))
+	assert.True(t, MatchesAddress(legacyAddress, bs("foo")))
+	assert.False(t, MatchesAddress(legacyAddress, bs("bar")))
+	assert.False(t, MatchesAddress(bs("short"), bs("foo")))
+}
+
+func TestMultibaseEncodings(t *testing.T) {
+	address := []byte{0x12, 0x20, 0xfb, 0xff, 0x00}
+	for name, expected := range map[string]string{
+		MultibaseBase16EncodingName:    "f1220fbff00",
+		MultibaseBase32EncodingName:    "bciqpx7ya",
+		MultibaseBase64URLEncodingName: "uEiD7_wA",
+	} {
+		encoding, err := GetAddressEncoding(name)
+		if assert.NoError(t, err) {
+			encoded := encoding.EncodeToString(address)
+			assert.Equal(t, expected, encoded, name)
+			decoded, err := encoding.DecodeString(encoded)
+			assert.NoError(t, err)
+			assert.Equal(t, address, decoded)
+		}
+	}
+
+	// Any supported multibase can be decoded
+	for _, encoded := range []string{"F1220FBFF00", "BCIQPX7YA", "mEiD7/wA",
+		"MEiD7/wA=", "UEiD7_wA="} {
+		decoded, err := DecodeMultibase(encoded)
+		assert.NoError(t, err, encoded)
+		assert.Equal(t, address, decoded, encoded)
+	}
+	_, err := DecodeMultibase("z2NEpo7TZRRrLZSi2U")
+	assert.Error(t, err)
+	_, err = DecodeMultibase("")
+	assert.Error(t, err)
+}
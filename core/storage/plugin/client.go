@@ -0,0 +1,269 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/logging"
+	"github.com/monax/hoard/core/logging/structure"
+	"github.com/monax/hoard/core/storage"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// The version of the storage plugin protocol defined in plugin.proto. Bump this
+// whenever a change is made to the protocol that existing plugins cannot
+// understand.
+const ProtocolVersion = 1
+
+// The environment variable through which a plugin is passed the path of the
+// Unix domain socket it should listen on
+const SocketEnvVar = "HOARD_PLUGIN_SOCKET"
+
+// The largest message (and so blob) that can be passed to or from a plugin
+const MaxMessageSize = 256 << 20
+
+// How long to wait for a plugin to start listening and complete the handshake
+const StartTimeout = 10 * time.Second
+
+// How long to wait for a plugin to exit after closing its STDIN before
+// killing it
+const StopTimeout = 5 * time.Second
+
+type pluginStore struct {
+	client     StoragePluginClient
+	conn       *grpc.ClientConn
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	exited     chan error
+	socketDir  string
+	pluginName string
+	logger     log.Logger
+}
+
+var _ storage.Store = (*pluginStore)(nil)
+var _ storage.Deleter = (*pluginStore)(nil)
+var _ storage.Lister = (*pluginStore)(nil)
+
+// Launch the executable command with args as a storage plugin and return a
+// Store that proxies to it. The plugin is passed the path of a Unix domain
+// socket to listen on in its environment (in addition to env and Hoard's own
+// environment) and is expected to exit when its STDIN is closed. Close must be
+// called to stop the plugin.
+func NewPluginStore(command string, args, env []string,
+	logger log.Logger) (*pluginStore, error) {
+
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+
+	socketDir, err := ioutil.TempDir("", "hoard-plugin-")
+	if err != nil {
+		return nil, err
+	}
+	socketPath := filepath.Join(socketDir, "plugin.sock")
+
+	cmd := exec.Command(command, args...)
+	cmd.Env = append(append(os.Environ(), env...),
+		fmt.Sprintf("%s=%s", SocketEnvVar, socketPath))
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		os.RemoveAll(socketDir)
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		os.RemoveAll(socketDir)
+		return nil, fmt.Errorf("Could not start storage plugin '%s': %s",
+			command, err)
+	}
+
+	ps := &pluginStore{
+		cmd:       cmd,
+		stdin:     stdin,
+		exited:    make(chan error, 1),
+		socketDir: socketDir,
+		logger: logging.TraceLogger(log.With(logger,
+			structure.ComponentKey, "storage")),
+	}
+	go func() {
+		ps.exited <- cmd.Wait()
+	}()
+
+	err = ps.connect(socketPath)
+	if err != nil {
+		ps.Close()
+		return nil, fmt.Errorf("Could not connect to storage plugin '%s': %s",
+			command, err)
+	}
+	ps.logger = log.With(ps.logger, "store_name", ps.Name())
+	return ps, nil
+}
+
+func (ps *pluginStore) connect(socketPath string) error {
+	err := ps.awaitSocket(socketPath)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(socketPath,
+		grpc.WithInsecure(),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(MaxMessageSize),
+			grpc.MaxCallSendMsgSize(MaxMessageSize)),
+		grpc.WithDialer(func(address string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", address, timeout)
+		}))
+	if err != nil {
+		return err
+	}
+	ps.conn = conn
+	ps.client = NewStoragePluginClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), StartTimeout)
+	defer cancel()
+	response, err := ps.client.Handshake(ctx, &HandshakeRequest{
+		ProtocolVersion: ProtocolVersion,
+	})
+	if err != nil {
+		return err
+	}
+	if response.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("Plugin speaks protocol version %v but this version "+
+			"of Hoard speaks protocol version %v", response.ProtocolVersion,
+			ProtocolVersion)
+	}
+	ps.pluginName = response.Name
+	return nil
+}
+
+// Wait for the plugin to start listening on socketPath, giving up early if the
+// plugin exits before it does
+func (ps *pluginStore) awaitSocket(socketPath string) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(StartTimeout)
+	for {
+		select {
+		case err := <-ps.exited:
+			// Make sure Close does not wait for an exit it has missed
+			ps.exited <- err
+			return fmt.Errorf("Plugin exited before listening on socket: %v", err)
+		case <-timeout:
+			return fmt.Errorf("Timed out after %s waiting for plugin to listen "+
+				"on socket", StartTimeout)
+		case <-ticker.C:
+			if _, err := os.Stat(socketPath); err == nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (ps *pluginStore) Put(address, data []byte) error {
+	_, err := ps.client.Put(context.Background(), &PutRequest{
+		Address: address,
+		Data:    data,
+	})
+	return err
+}
+
+func (ps *pluginStore) Get(address []byte) ([]byte, error) {
+	data, err := ps.client.Get(context.Background(), &Address{
+		Address: address,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.Data, nil
+}
+
+func (ps *pluginStore) Stat(address []byte) (*storage.StatInfo, error) {
+	statInfo, err := ps.client.Stat(context.Background(), &Address{
+		Address: address,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &storage.StatInfo{
+		Exists: statInfo.Exists,
+		Size:   statInfo.Size,
+	}, nil
+}
+
+func (ps *pluginStore) Location(address []byte) string {
+	locationInfo, err := ps.client.Location(context.Background(), &Address{
+		Address: address,
+	})
+	if err != nil {
+		ps.logger.Log("method", "Location", "error", err)
+		return ""
+	}
+	return locationInfo.Location
+}
+
+// Plugins whose store does not support deletion (or that predate the Delete
+// call) return an Unimplemented status
+func (ps *pluginStore) Delete(address []byte) error {
+	_, err := ps.client.Delete(context.Background(), &Address{
+		Address: address,
+	})
+	return err
+}
+
+// Plugins whose store does not support listing (or that predate the List
+// call) return an Unimplemented status
+func (ps *pluginStore) List(fn func(address []byte, err error) error) error {
+	// Cancelling stops the plugin streaming addresses if fn stops listing early
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := ps.client.List(ctx, &ListRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if entry.Error != "" {
+			err = fn(nil, errors.New(entry.Error))
+		} else {
+			err = fn(entry.Address, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (ps *pluginStore) Name() string {
+	return fmt.Sprintf("pluginStore[command=%s]<%s>", ps.cmd.Path,
+		ps.pluginName)
+}
+
+// Stop the plugin by closing its STDIN, killing it if it does not exit in time
+func (ps *pluginStore) Close() error {
+	if ps.conn != nil {
+		ps.conn.Close()
+	}
+	ps.stdin.Close()
+	defer os.RemoveAll(ps.socketDir)
+	select {
+	case <-ps.exited:
+		return nil
+	case <-time.After(StopTimeout):
+		return ps.cmd.Process.Kill()
+	}
+}
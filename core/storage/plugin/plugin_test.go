@@ -0,0 +1,94 @@
+package plugin_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/monax/hoard/core/storage"
+	"github.com/monax/hoard/core/storage/plugin"
+	"github.com/monax/hoard/core/storage/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Set when this test binary is launched by a test as a storage plugin, to
+// "minimal" to serve a store without optional capabilities
+const servePluginEnvVar = "HOARD_TEST_SERVE_PLUGIN"
+
+// Hides the optional interfaces of the Store it embeds
+type minimalStore struct {
+	storage.Store
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(servePluginEnvVar) != "" {
+		var store storage.Store = storage.NewMemoryStore()
+		if os.Getenv(servePluginEnvVar) == "minimal" {
+			store = minimalStore{store}
+		}
+		err := plugin.Serve(store)
+		if err != nil {
+			panic(err)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestPluginStore(t *testing.T) {
+	plugintest.TestPlugin(t, os.Args[0], nil,
+		[]string{servePluginEnvVar + "=1"})
+}
+
+func TestPluginStoreName(t *testing.T) {
+	store, err := plugin.NewPluginStore(os.Args[0], nil,
+		[]string{servePluginEnvVar + "=1"}, nil)
+	if assert.NoError(t, err) {
+		assert.Contains(t, store.Name(), "memoryStore")
+		assert.NoError(t, store.Close())
+	}
+}
+
+func TestPluginStoreNotAPlugin(t *testing.T) {
+	// A plugin that exits without serving should fail rather than hang
+	_, err := plugin.NewPluginStore("true", nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestPluginStoreList(t *testing.T) {
+	store, err := plugin.NewPluginStore(os.Args[0], nil,
+		[]string{servePluginEnvVar + "=1"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.Close()
+	for _, address := range []string{"a", "b", "c"} {
+		assert.NoError(t, store.Put([]byte(address), []byte("data")))
+	}
+	// Listing can be stopped early
+	stop := errors.New("stop")
+	listed := 0
+	err = storage.List(store, func(address []byte, err error) error {
+		listed++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, listed)
+}
+
+func TestPluginStoreOptionalUnimplemented(t *testing.T) {
+	store, err := plugin.NewPluginStore(os.Args[0], nil,
+		[]string{servePluginEnvVar + "=minimal"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.Close()
+	err = storage.List(store, func(address []byte, err error) error {
+		return nil
+	})
+	assert.Equal(t, codes.Unimplemented, grpc.Code(err))
+	err = storage.Delete(store, []byte("address"))
+	assert.Equal(t, codes.Unimplemented, grpc.Code(err))
+}
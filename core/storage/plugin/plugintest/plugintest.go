@@ -0,0 +1,36 @@
+// Conformance tests for storage plugins. Plugin authors can check their plugin
+// speaks the protocol correctly and behaves as Hoard expects a Store to with:
+//
+//	func TestPlugin(t *testing.T) {
+//		plugintest.TestPlugin(t, "path/to/plugin", []string{"--some-arg"}, nil)
+//	}
+package plugintest
+
+import (
+	"testing"
+
+	"github.com/monax/hoard/core/storage"
+	"github.com/monax/hoard/core/storage/plugin"
+	"github.com/monax/hoard/core/storage/storagetest"
+)
+
+// Launch the plugin executable command and run the conformance checks against
+// it. The plugin is expected to start with an empty store.
+func TestPlugin(t *testing.T, command string, args, env []string) {
+	store, err := plugin.NewPluginStore(command, args, env, nil)
+	if err != nil {
+		t.Fatalf("Could not start plugin: %s", err)
+	}
+	defer func() {
+		err := store.Close()
+		if err != nil {
+			t.Errorf("Could not stop plugin: %s", err)
+		}
+	}()
+	TestStore(t, store)
+}
+
+// Check that store behaves as Hoard expects of a Store
+func TestStore(t *testing.T, store storage.Store) {
+	storagetest.TestStore(t, store)
+}
@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+
+	"github.com/monax/hoard/core/storage"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Serve store as a storage plugin. This is intended to be called from the main
+// function of a plugin executable launched by Hoard, it listens on the socket
+// passed by Hoard and returns once Hoard closes the plugin's STDIN (which
+// will also happen if Hoard exits).
+func Serve(store storage.Store) error {
+	socketPath := os.Getenv(SocketEnvVar)
+	if socketPath == "" {
+		return fmt.Errorf("Expected the socket path to be passed in $%s, "+
+			"storage plugins are intended to be launched by Hoard", SocketEnvVar)
+	}
+	return ServeOn(socketPath, store, os.Stdin)
+}
+
+// Serve store as a storage plugin on a Unix domain socket at socketPath until
+// done reaches EOF or errors
+func ServeOn(socketPath string, store storage.Store, done io.Reader) error {
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("Storage plugin could not listen on '%s': %s",
+			socketPath, err)
+	}
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(MaxMessageSize),
+		grpc.MaxSendMsgSize(MaxMessageSize))
+	RegisterStoragePluginServer(grpcServer, NewStoragePluginServer(store))
+	stopped := make(chan struct{})
+	go func() {
+		// We never expect to read any content, just to be told to stop
+		io.Copy(ioutil.Discard, done)
+		close(stopped)
+		grpcServer.Stop()
+	}()
+	err = grpcServer.Serve(listener)
+	select {
+	case <-stopped:
+		// Serve always returns an error, but we were asked to stop
+		return nil
+	default:
+		return err
+	}
+}
+
+type storagePluginServer struct {
+	store storage.Store
+}
+
+// Plumbs a Store into the StoragePlugin GRPC service
+func NewStoragePluginServer(store storage.Store) StoragePluginServer {
+	return &storagePluginServer{
+		store: store,
+	}
+}
+
+func (sps *storagePluginServer) Handshake(ctx context.Context,
+	request *HandshakeRequest) (*HandshakeResponse, error) {
+	return &HandshakeResponse{
+		ProtocolVersion: ProtocolVersion,
+		Name:            sps.store.Name(),
+	}, nil
+}
+
+func (sps *storagePluginServer) Put(ctx context.Context,
+	request *PutRequest) (*PutResponse, error) {
+	err := sps.store.Put(request.Address, request.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &PutResponse{}, nil
+}
+
+func (sps *storagePluginServer) Get(ctx context.Context,
+	address *Address) (*Data, error) {
+	data, err := sps.store.Get(address.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &Data{
+		Data: data,
+	}, nil
+}
+
+func (sps *storagePluginServer) Stat(ctx context.Context,
+	address *Address) (*StatInfo, error) {
+	statInfo, err := sps.store.Stat(address.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatInfo{
+		Exists: statInfo.Exists,
+		Size:   statInfo.Size,
+	}, nil
+}
+
+func (sps *storagePluginServer) Location(ctx context.Context,
+	address *Address) (*LocationInfo, error) {
+	return &LocationInfo{
+		Location: sps.store.Location(address.Address),
+	}, nil
+}
+
+func (sps *storagePluginServer) Delete(ctx context.Context,
+	address *Address) (*DeleteResponse, error) {
+	err := storage.Delete(sps.store, address.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteResponse{}, nil
+}
+
+func (sps *storagePluginServer) List(request *ListRequest,
+	stream StoragePlugin_ListServer) error {
+	return storage.List(sps.store, func(address []byte, err error) error {
+		entry := &ListEntry{
+			Address: address,
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		return stream.Send(entry)
+	})
+}
@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func ErrorCircuitOpen(store Store) error {
+	return status.Errorf(codes.Unavailable, "%s is failing so calls to it "+
+		"are being refused until it recovers", store.Name())
+}
+
+func ErrorOperationTimeout(store Store, method string,
+	timeout time.Duration) error {
+	return status.Errorf(codes.DeadlineExceeded, "%s on %s did not complete "+
+		"within %s", method, store.Name(), timeout)
+}
+
+// Policies governing how a resilient store calls through to its underlying
+// store. Zero values disable the corresponding policy.
+type ResiliencePolicy struct {
+	// Maximum number of times to attempt each operation (including the first)
+	MaxAttempts int
+	// Backoff before the first retry, doubled for each retry thereafter. The
+	// actual wait is drawn uniformly from between zero and the backoff (jitter).
+	InitialBackoff time.Duration
+	// Upper bound on the backoff between retries
+	MaxBackoff time.Duration
+	// Time after which a single attempt is abandoned
+	Timeout time.Duration
+	// Maximum number of calls in flight to the underlying store at once
+	MaxConcurrency int
+	// Number of consecutive failed operations after which the circuit breaker
+	// trips and further calls fail immediately
+	BreakerThreshold int
+	// How long the circuit breaker stays tripped before letting a trial call
+	// through to test whether the underlying store has recovered
+	BreakerCooldown time.Duration
+}
+
+type resilientStore struct {
+	store     Store
+	policy    ResiliencePolicy
+	semaphore chan struct{}
+	sync.Mutex
+	// Consecutive failed operations
+	failures int
+	// When the breaker was tripped, zero if it is closed
+	trippedAt time.Time
+	// Whether a trial call is in flight while tripped
+	trialling bool
+	logger    log.Logger
+}
+
+// Decorates store with retries for transient errors, timeouts, a limit on
+// concurrent calls, and a circuit breaker according to policy. All Store
+// operations are idempotent so all are retried. Errors indicating that a call
+// could never succeed (such as NotFound or DataLoss) are neither retried nor
+// counted as failures by the circuit breaker.
+//
+// Since a Store cannot be cancelled an attempt that times out continues to
+// run in the background and occupies its concurrency slot until it returns.
+func NewResilientStore(store Store, policy ResiliencePolicy,
+	logger log.Logger) *resilientStore {
+
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	rs := &resilientStore{
+		store:  store,
+		policy: policy,
+	}
+	if policy.MaxConcurrency > 0 {
+		rs.semaphore = make(chan struct{}, policy.MaxConcurrency)
+	}
+	rs.logger = log.With(logger, "module", "storage", "store", rs.Name())
+	return rs
+}
+
+var _ Store = (*resilientStore)(nil)
+
+func (rs *resilientStore) Get(address []byte) ([]byte, error) {
+	var data []byte
+	err := rs.call("Get", func() error {
+		var err error
+		data, err = rs.store.Get(address)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (rs *resilientStore) Stat(address []byte) (*StatInfo, error) {
+	var statInfo *StatInfo
+	err := rs.call("Stat", func() error {
+		var err error
+		statInfo, err = rs.store.Stat(address)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statInfo, nil
+}
+
+func (rs *resilientStore) Put(address, data []byte) error {
+	return rs.call("Put", func() error {
+		return rs.store.Put(address, data)
+	})
+}
+
+func (rs *resilientStore) Delete(address []byte) error {
+	return rs.call("Delete", func() error {
+		return Delete(rs.store, address)
+	})
+}
+
+func (rs *resilientStore) List(fn func(address []byte, err error) error) error {
+	return List(rs.store, fn)
+}
+
+func (rs *resilientStore) Location(address []byte) string {
+	return rs.store.Location(address)
+}
+
+func (rs *resilientStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	return Presign(rs.store, address, expiry)
+}
+
+func (rs *resilientStore) Snapshot() error {
+	return Snapshot(rs.store)
+}
+
+func (rs *resilientStore) Close() error {
+	return Close(rs.store)
+}
+
+func (rs *resilientStore) Name() string {
+	return fmt.Sprintf("resilientStore[attempts=%v,timeout=%s,concurrency=%v]<%s>",
+		rs.policy.MaxAttempts, rs.policy.Timeout, rs.policy.MaxConcurrency,
+		rs.store.Name())
+}
+
+// Make attempts at calling operation according to policy, the results of
+// operation should be captured in its closure
+func (rs *resilientStore) call(method string, operation func() error) error {
+	attempts := rs.policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := rs.policy.InitialBackoff
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if !rs.allow() {
+			return ErrorCircuitOpen(rs.store)
+		}
+		err = rs.attempt(method, operation)
+		if !isTransient(err) {
+			rs.recordSuccess()
+			return err
+		}
+		rs.recordFailure()
+		if attempt < attempts {
+			logging.TraceMsg(rs.logger, "Retrying failed operation",
+				"method", method,
+				"attempt", attempt,
+				"error", err)
+			if backoff > 0 {
+				time.Sleep(time.Duration(rand.Int63n(int64(backoff)) + 1))
+				backoff *= 2
+				if rs.policy.MaxBackoff > 0 && backoff > rs.policy.MaxBackoff {
+					backoff = rs.policy.MaxBackoff
+				}
+			}
+		}
+	}
+	return err
+}
+
+// Make a single call to operation within any timeout and concurrency limit
+func (rs *resilientStore) attempt(method string, operation func() error) error {
+	var timeout <-chan time.Time
+	if rs.policy.Timeout > 0 {
+		timer := time.NewTimer(rs.policy.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	if rs.semaphore != nil {
+		select {
+		case rs.semaphore <- struct{}{}:
+		case <-timeout:
+			return ErrorOperationTimeout(rs.store, method, rs.policy.Timeout)
+		}
+	}
+
+	if timeout == nil {
+		defer rs.release()
+		return operation()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer rs.release()
+		done <- operation()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-timeout:
+		return ErrorOperationTimeout(rs.store, method, rs.policy.Timeout)
+	}
+}
+
+func (rs *resilientStore) release() {
+	if rs.semaphore != nil {
+		<-rs.semaphore
+	}
+}
+
+// Whether the circuit breaker lets a call through
+func (rs *resilientStore) allow() bool {
+	rs.Lock()
+	defer rs.Unlock()
+	if rs.trippedAt.IsZero() {
+		return true
+	}
+	if rs.trialling || time.Since(rs.trippedAt) < rs.policy.BreakerCooldown {
+		return false
+	}
+	rs.trialling = true
+	return true
+}
+
+func (rs *resilientStore) recordSuccess() {
+	rs.Lock()
+	defer rs.Unlock()
+	if !rs.trippedAt.IsZero() {
+		logging.InfoMsg(rs.logger, "Circuit breaker reset")
+	}
+	rs.failures = 0
+	rs.trippedAt = time.Time{}
+	rs.trialling = false
+}
+
+func (rs *resilientStore) recordFailure() {
+	rs.Lock()
+	defer rs.Unlock()
+	rs.failures++
+	if rs.trialling {
+		// Trial call failed so wait out another cooldown
+		rs.trippedAt = time.Now()
+		rs.trialling = false
+		return
+	}
+	if rs.policy.BreakerThreshold > 0 && rs.trippedAt.IsZero() &&
+		rs.failures >= rs.policy.BreakerThreshold {
+		rs.trippedAt = time.Now()
+		logging.InfoMsg(rs.logger, "Circuit breaker tripped",
+			"consecutive_failures", rs.failures,
+			"cooldown", rs.policy.BreakerCooldown)
+	}
+}
+
+// Whether err may not recur if the operation is tried again. Only errors known
+// to be transient are retried (or count towards tripping the circuit breaker)
+// since retrying anything else may repeat an operation that will never succeed
+// or that should not be repeated.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if netErr, ok := err.(net.Error); ok {
+		return netErr.Temporary()
+	}
+	if awsErr, ok := err.(awserr.Error); ok {
+		return isTransientAWSError(awsErr)
+	}
+	switch grpc.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Errors from AWS backends (such as S3) carry AWS error codes and HTTP statuses
+// rather than gRPC codes, so throttling, timeouts, and server errors are
+// recognised from those
+func isTransientAWSError(err awserr.Error) bool {
+	if request.IsErrorRetryable(err) || request.IsErrorThrottle(err) {
+		return true
+	}
+	if requestFailure, ok := err.(awserr.RequestFailure); ok {
+		return requestFailure.StatusCode() >= http.StatusInternalServerError ||
+			requestFailure.StatusCode() == http.StatusTooManyRequests
+	}
+	return false
+}
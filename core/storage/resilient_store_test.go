@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Fails the next failures calls (with err if set) and delays each call by delay
+type flakyStore struct {
+	Store
+	sync.Mutex
+	failures int
+	calls    int
+	delay    time.Duration
+	err      error
+}
+
+func (fs *flakyStore) Get(address []byte) ([]byte, error) {
+	fs.Lock()
+	fs.calls++
+	fail := fs.failures > 0
+	if fail {
+		fs.failures--
+	}
+	fs.Unlock()
+	time.Sleep(fs.delay)
+	if fail {
+		if fs.err != nil {
+			return nil, fs.err
+		}
+		return nil, status.Error(codes.Unavailable, "transient failure")
+	}
+	return fs.Store.Get(address)
+}
+
+func TestResilientStoreRetries(t *testing.T) {
+	fs := &flakyStore{Store: NewMemoryStore()}
+	rs := NewResilientStore(fs, ResiliencePolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}, nil)
+	testStore(t, rs)
+
+	fs.calls = 0
+	fs.failures = 2
+	data, err := rs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("data"), data)
+	assert.Equal(t, 3, fs.calls)
+
+	fs.calls = 0
+	fs.failures = 3
+	_, err = rs.Get(bs("address"))
+	assert.Error(t, err)
+	assert.Equal(t, 3, fs.calls)
+
+	// Not retried
+	fs.calls = 0
+	_, err = rs.Get(bs("missing"))
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	assert.Equal(t, 1, fs.calls)
+}
+
+func TestResilientStoreRetriesAWSErrors(t *testing.T) {
+	fs := &flakyStore{Store: NewMemoryStore()}
+	rs := NewResilientStore(fs, ResiliencePolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}, nil)
+	assert.NoError(t, rs.Put(bs("address"), bs("data")))
+
+	// S3 throttling comes back as a 503
+	fs.err = awserr.NewRequestFailure(awserr.New("SlowDown",
+		"Please reduce your request rate.", nil), 503, "request-id")
+	fs.failures = 2
+	data, err := rs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("data"), data)
+	assert.Equal(t, 3, fs.calls)
+
+	// Not retried
+	fs.calls = 0
+	fs.err = awserr.NewRequestFailure(awserr.New("AccessDenied",
+		"Access Denied", nil), 403, "request-id")
+	fs.failures = 1
+	_, err = rs.Get(bs("address"))
+	assert.Equal(t, fs.err, err)
+	assert.Equal(t, 1, fs.calls)
+}
+
+func TestResilientStoreTimeout(t *testing.T) {
+	fs := &flakyStore{Store: NewMemoryStore(), delay: 50 * time.Millisecond}
+	rs := NewResilientStore(fs, ResiliencePolicy{
+		Timeout: time.Millisecond,
+	}, nil)
+	_, err := rs.Get(bs("address"))
+	assert.Equal(t, codes.DeadlineExceeded, grpc.Code(err))
+}
+
+func TestResilientStoreConcurrency(t *testing.T) {
+	fs := &flakyStore{Store: NewMemoryStore(), delay: 50 * time.Millisecond}
+	rs := NewResilientStore(fs, ResiliencePolicy{
+		Timeout:        10 * time.Millisecond,
+		MaxConcurrency: 1,
+	}, nil)
+	// First call times out but keeps hold of the only slot
+	_, err := rs.Get(bs("address"))
+	assert.Equal(t, codes.DeadlineExceeded, grpc.Code(err))
+	_, err = rs.Get(bs("address"))
+	assert.Equal(t, codes.DeadlineExceeded, grpc.Code(err))
+	fs.Lock()
+	assert.Equal(t, 1, fs.calls)
+	fs.Unlock()
+}
+
+func TestResilientStoreCircuitBreaker(t *testing.T) {
+	fs := &flakyStore{Store: NewMemoryStore(), failures: 2}
+	rs := NewResilientStore(fs, ResiliencePolicy{
+		BreakerThreshold: 2,
+		BreakerCooldown:  20 * time.Millisecond,
+	}, nil)
+	assert.NoError(t, rs.Put(bs("address"), bs("data")))
+
+	_, err := rs.Get(bs("address"))
+	assert.Error(t, err)
+	_, err = rs.Get(bs("address"))
+	assert.Error(t, err)
+
+	// Tripped
+	_, err = rs.Get(bs("address"))
+	assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	assert.Equal(t, 2, fs.calls)
+
+	time.Sleep(20 * time.Millisecond)
+	data, err := rs.Get(bs("address"))
+	assert.NoError(t, err)
+	assert.Equal(t, bs("data"), data)
+}
+
+type netError struct {
+	temporary bool
+}
+
+func (ne netError) Error() string   { return "network error" }
+func (ne netError) Timeout() bool   { return false }
+func (ne netError) Temporary() bool { return ne.temporary }
+
+var _ net.Error = netError{}
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, isTransient(nil))
+	for _, code := range []codes.Code{codes.Unavailable, codes.DeadlineExceeded,
+		codes.ResourceExhausted, codes.Aborted} {
+		assert.True(t, isTransient(status.Error(code, "")), "%v", code)
+	}
+	for _, code := range []codes.Code{codes.Unknown, codes.NotFound,
+		codes.DataLoss, codes.Internal, codes.InvalidArgument} {
+		assert.False(t, isTransient(status.Error(code, "")), "%v", code)
+	}
+	// Errors without a code may not be safe to retry
+	assert.False(t, isTransient(errors.New("failure")))
+	assert.True(t, isTransient(netError{temporary: true}))
+	assert.True(t, isTransient(awserr.New("RequestError", "send request failed",
+		nil)))
+	assert.True(t, isTransient(awserr.New("Throttling", "Rate exceeded", nil)))
+	assert.True(t, isTransient(awserr.NewRequestFailure(awserr.New(
+		"InternalError", "", nil), 500, "")))
+	assert.True(t, isTransient(awserr.NewRequestFailure(awserr.New(
+		"TooManyRequests", "", nil), 429, "")))
+	assert.False(t, isTransient(awserr.NewRequestFailure(awserr.New(
+		"NoSuchBucket", "", nil), 404, "")))
+	assert.False(t, isTransient(netError{temporary: false}))
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"bytes"
 
@@ -19,33 +22,90 @@ type s3Store struct {
 	awsS3           *s3.S3
 	awsUploader     *s3manager.Uploader
 	awsDownloader   *s3manager.Downloader
+	awsConfig       *aws.Config
+	uploadOptions   *S3UploadOptions
 	s3Bucket        string
 	s3Prefix        string
 	addressEncoding AddressEncoding
 	logger          log.Logger
 }
 
+var _ Store = (*s3Store)(nil)
+var _ Presigner = (*s3Store)(nil)
+var _ Deleter = (*s3Store)(nil)
+var _ Lister = (*s3Store)(nil)
+
 const NotFoundCode = "NotFound"
 
+// The longest expiry S3 allows for a presigned URL
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+const (
+	// Server-side encryption with S3-managed keys (SSE-S3)
+	SSES3 = s3.ServerSideEncryptionAes256
+	// Server-side encryption with KMS-managed keys (SSE-KMS)
+	SSEKMS = s3.ServerSideEncryptionAwsKms
+)
+
+// Options applied when uploading blobs to S3. The zero value uses the bucket
+// defaults and the default multipart upload tuning of the AWS SDK.
+type S3UploadOptions struct {
+	// Server-side encryption algorithm, one of SSES3 or SSEKMS if non-empty
+	ServerSideEncryption string
+	// The KMS key to use with SSEKMS, the account default key is used if empty
+	SSEKMSKeyID string
+	// Storage class such as STANDARD_IA, the bucket default is used if empty
+	StorageClass string
+	// The size in bytes of each part of a multipart upload, must be at least
+	// s3manager.MinUploadPartSize if non-zero
+	PartSize int64
+	// The number of parts of a single upload that are uploaded concurrently
+	Concurrency int
+}
+
+// Create a Store backed by the S3 bucket s3Bucket with addresses encoded with
+// addressEncoding and stored under s3Prefix. To target an S3-compatible
+// service such as MinIO or Ceph set Endpoint (and probably S3ForcePathStyle)
+// in awsConfig. Either awsConfig or uploadOptions may be nil to use defaults.
 func NewS3Store(s3Bucket, s3Prefix string, addressEncoding AddressEncoding,
-	awsConfig *aws.Config, logger log.Logger) (*s3Store, error) {
+	awsConfig *aws.Config, uploadOptions *S3UploadOptions,
+	logger log.Logger) (*s3Store, error) {
 
 	if awsConfig == nil {
 		awsConfig = aws.NewConfig()
 	}
 
+	if uploadOptions == nil {
+		uploadOptions = new(S3UploadOptions)
+	}
+
 	if logger == nil {
 		logger = log.NewNopLogger()
 	}
 
+	err := uploadOptions.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	awsSession, err := Session(awsConfig)
 	if err != nil {
 		return nil, err
 	}
 	s3s := &s3Store{
-		awsS3:           s3.New(awsSession),
-		awsUploader:     s3manager.NewUploader(awsSession),
+		awsS3: s3.New(awsSession),
+		awsUploader: s3manager.NewUploader(awsSession,
+			func(uploader *s3manager.Uploader) {
+				if uploadOptions.PartSize != 0 {
+					uploader.PartSize = uploadOptions.PartSize
+				}
+				if uploadOptions.Concurrency != 0 {
+					uploader.Concurrency = uploadOptions.Concurrency
+				}
+			}),
 		awsDownloader:   s3manager.NewDownloader(awsSession),
+		awsConfig:       awsConfig,
+		uploadOptions:   uploadOptions,
 		s3Bucket:        s3Bucket,
 		s3Prefix:        s3Prefix,
 		addressEncoding: addressEncoding,
@@ -56,6 +116,28 @@ func NewS3Store(s3Bucket, s3Prefix string, addressEncoding AddressEncoding,
 	return s3s, nil
 }
 
+func (uo *S3UploadOptions) Validate() error {
+	switch uo.ServerSideEncryption {
+	case "", SSES3, SSEKMS:
+	default:
+		return fmt.Errorf("ServerSideEncryption must be one of '%s' or '%s' if "+
+			"set but got '%s'", SSES3, SSEKMS, uo.ServerSideEncryption)
+	}
+	if uo.SSEKMSKeyID != "" && uo.ServerSideEncryption != SSEKMS {
+		return fmt.Errorf("SSEKMSKeyID can only be set when ServerSideEncryption "+
+			"is '%s'", SSEKMS)
+	}
+	if uo.PartSize != 0 && uo.PartSize < s3manager.MinUploadPartSize {
+		return fmt.Errorf("PartSize must be at least %v bytes but got %v",
+			s3manager.MinUploadPartSize, uo.PartSize)
+	}
+	if uo.Concurrency < 0 {
+		return fmt.Errorf("Concurrency must be non-negative but got %v",
+			uo.Concurrency)
+	}
+	return nil
+}
+
 func Session(awsConfig *aws.Config) (*session.Session, error) {
 	return session.NewSessionWithOptions(session.Options{
 		Config:            *awsConfig,
@@ -66,9 +148,12 @@ func Session(awsConfig *aws.Config) (*session.Session, error) {
 func (s3s *s3Store) Put(address, data []byte) error {
 	// Should be threadsafe
 	output, err := s3s.awsUploader.Upload(&s3manager.UploadInput{
-		Bucket: &s3s.s3Bucket,
-		Key:    aws.String(s3s.Key(address)),
-		Body:   bytes.NewReader(data),
+		Bucket:               &s3s.s3Bucket,
+		Key:                  aws.String(s3s.Key(address)),
+		Body:                 bytes.NewReader(data),
+		ServerSideEncryption: optionalString(s3s.uploadOptions.ServerSideEncryption),
+		SSEKMSKeyId:          optionalString(s3s.uploadOptions.SSEKMSKeyID),
+		StorageClass:         optionalString(s3s.uploadOptions.StorageClass),
 	})
 	if err != nil {
 		return err
@@ -91,6 +176,10 @@ func (s3s *s3Store) Get(address []byte) ([]byte, error) {
 		"encoded_address", s3s.encode(address),
 		"downloaded_bytes", n)
 	if err != nil {
+		s3err, ok := err.(awserr.Error)
+		if ok && s3err.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrorAddressNotFound(address)
+		}
 		return nil, err
 	}
 	return buf.Bytes(), nil
@@ -120,9 +209,90 @@ func (s3s *s3Store) Stat(address []byte) (*StatInfo, error) {
 	}, nil
 }
 
+func (s3s *s3Store) Delete(address []byte) error {
+	_, err := s3s.awsS3.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: &s3s.s3Bucket,
+		Key:    aws.String(s3s.Key(address)),
+	})
+	s3s.logger.Log("method", "Delete",
+		"encoded_address", s3s.encode(address))
+	return err
+}
+
+func (s3s *s3Store) List(fn func(address []byte, err error) error) error {
+	keyPrefix := s3s.s3Prefix + "/"
+	var fnErr error
+	err := s3s.awsS3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: &s3s.s3Bucket,
+		Prefix: aws.String(keyPrefix),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range output.Contents {
+			key := aws.StringValue(object.Key)
+			address, err := s3s.addressEncoding.DecodeString(
+				strings.TrimPrefix(key, keyPrefix))
+			if err != nil {
+				fnErr = fn(nil, fmt.Errorf("Could not decode key '%s' as an "+
+					"address: %s", key, err))
+			} else {
+				fnErr = fn(address, nil)
+			}
+			if fnErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	s3s.logger.Log("method", "List")
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
 func (s3s *s3Store) Location(address []byte) string {
-	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", s3s.s3Bucket,
-		s3s.Key(address))
+	endpoint := aws.StringValue(s3s.awsConfig.Endpoint)
+	if endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", s3s.s3Bucket,
+			s3s.Key(address))
+	}
+	// Like the AWS SDK we only use DisableSSL to choose a scheme when the
+	// endpoint does not include one
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https"
+		if aws.BoolValue(s3s.awsConfig.DisableSSL) {
+			scheme = "http"
+		}
+		endpoint = fmt.Sprintf("%s://%s", scheme, endpoint)
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Sprintf("%s/%s/%s", endpoint, s3s.s3Bucket, s3s.Key(address))
+	}
+	if aws.BoolValue(s3s.awsConfig.S3ForcePathStyle) {
+		return fmt.Sprintf("%s://%s/%s/%s", endpointURL.Scheme,
+			endpointURL.Host, s3s.s3Bucket, s3s.Key(address))
+	}
+	return fmt.Sprintf("%s://%s.%s/%s", endpointURL.Scheme, s3s.s3Bucket,
+		endpointURL.Host, s3s.Key(address))
+}
+
+func (s3s *s3Store) Presign(address []byte, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > MaxPresignExpiry {
+		return "", fmt.Errorf("Presigned URL expiry must be positive and no "+
+			"longer than %s but got %s", MaxPresignExpiry, expiry)
+	}
+	request, _ := s3s.awsS3.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: &s3s.s3Bucket,
+		Key:    aws.String(s3s.Key(address)),
+	})
+	presignedURL, err := request.Presign(expiry)
+	s3s.logger.Log("method", "Presign",
+		"encoded_address", s3s.encode(address),
+		"expiry", expiry)
+	if err != nil {
+		return "", err
+	}
+	return presignedURL, nil
 }
 
 func (s3s *s3Store) Key(address []byte) string {
@@ -137,3 +307,11 @@ func (s3s *s3Store) Name() string {
 func (s3s *s3Store) encode(address []byte) string {
 	return s3s.addressEncoding.EncodeToString(address)
 }
+
+// The AWS SDK distinguishes unset parameters by nil
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
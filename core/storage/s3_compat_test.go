@@ -0,0 +1,132 @@
+package storage_test
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/monax/hoard/core/storage"
+	"github.com/monax/hoard/core/storage/s3test"
+	"github.com/monax/hoard/core/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3StoreConformance(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+
+	s3s, err := server.NewS3Store("bucket", "base64", base64.URLEncoding, nil)
+	if assert.NoError(t, err) {
+		storagetest.TestStore(t, s3s)
+	}
+	s3s, err = server.NewS3Store("bucket", "base32", base32.StdEncoding, nil)
+	if assert.NoError(t, err) {
+		storagetest.TestStore(t, s3s)
+	}
+	for _, key := range server.Keys("bucket") {
+		assert.True(t, strings.HasPrefix(key, "base64/") ||
+			strings.HasPrefix(key, "base32/"), key)
+	}
+}
+
+func TestS3StoreMultipart(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+
+	s3s, err := server.NewS3Store("bucket", "prefix", base32.StdEncoding,
+		&storage.S3UploadOptions{
+			PartSize:    5 << 20,
+			Concurrency: 3,
+		})
+	assert.NoError(t, err)
+
+	// Uploaded in 4 parts and downloaded in 4 ranges
+	data := bytes.Repeat([]byte("0123456789abcdef"), (16<<20)/16+7)
+	assert.NoError(t, s3s.Put([]byte("address"), data))
+	retrieved, err := s3s.Get([]byte("address"))
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(data, retrieved))
+
+	object := server.Object("bucket",
+		"prefix/"+base32.StdEncoding.EncodeToString([]byte("address")))
+	if assert.NotNil(t, object) {
+		assert.Len(t, object.Data, len(data))
+	}
+}
+
+func TestS3StoreUploadOptions(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+
+	s3s, err := server.NewS3Store("bucket", "prefix", base32.StdEncoding,
+		&storage.S3UploadOptions{
+			ServerSideEncryption: storage.SSEKMS,
+			SSEKMSKeyID:          "key-id",
+			StorageClass:         "STANDARD_IA",
+		})
+	assert.NoError(t, err)
+	assert.NoError(t, s3s.Put([]byte("address"), []byte("data")))
+
+	object := server.Object("bucket",
+		"prefix/"+base32.StdEncoding.EncodeToString([]byte("address")))
+	if assert.NotNil(t, object) {
+		assert.Equal(t, storage.SSEKMS,
+			object.Header.Get("X-Amz-Server-Side-Encryption"))
+		assert.Equal(t, "key-id",
+			object.Header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+		assert.Equal(t, "STANDARD_IA", object.Header.Get("X-Amz-Storage-Class"))
+	}
+
+	assert.Equal(t, server.URL+"/bucket/prefix/MFSGI4TFONZQ====",
+		s3s.Location([]byte("address")))
+
+	presignedURL, err := storage.Presign(s3s, []byte("address"), time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(presignedURL,
+		server.URL+"/bucket/prefix/MFSGI4TFONZQ%3D%3D%3D%3D?"), presignedURL)
+	assert.Contains(t, presignedURL, "X-Amz-Expires=60")
+
+	_, err = storage.Presign(s3s, []byte("address"), 8*24*time.Hour)
+	assert.Error(t, err)
+}
+
+func TestS3StoreLocation(t *testing.T) {
+	address := []byte("address")
+	s3s, err := storage.NewS3Store("bucket", "prefix", base32.StdEncoding, nil,
+		nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://bucket.s3.amazonaws.com/prefix/MFSGI4TFONZQ====",
+		s3s.Location(address))
+
+	s3s, err = storage.NewS3Store("bucket", "prefix", base32.StdEncoding,
+		aws.NewConfig().WithEndpoint("minio.example.com:9000"), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://bucket.minio.example.com:9000/prefix/MFSGI4TFONZQ====",
+		s3s.Location(address))
+
+	s3s, err = storage.NewS3Store("bucket", "prefix", base32.StdEncoding,
+		aws.NewConfig().WithEndpoint("minio.example.com:9000").
+			WithDisableSSL(true).WithS3ForcePathStyle(true), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://minio.example.com:9000/bucket/prefix/MFSGI4TFONZQ====",
+		s3s.Location(address))
+}
+
+func TestS3UploadOptionsValidate(t *testing.T) {
+	assert.NoError(t, new(storage.S3UploadOptions).Validate())
+	assert.NoError(t, (&storage.S3UploadOptions{
+		ServerSideEncryption: storage.SSES3,
+	}).Validate())
+	assert.Error(t, (&storage.S3UploadOptions{
+		ServerSideEncryption: "rot13",
+	}).Validate())
+	assert.Error(t, (&storage.S3UploadOptions{
+		ServerSideEncryption: storage.SSES3,
+		SSEKMSKeyID:          "key-id",
+	}).Validate())
+	assert.Error(t, (&storage.S3UploadOptions{PartSize: 1024}).Validate())
+}
@@ -20,7 +20,7 @@ func TestS3Store(t *testing.T) {
 	bucket := "monax-hoard-test"
 	prefix := "TestS3Store/"
 	deletePrefix(bucket, prefix)
-	s3s, err := NewS3Store(bucket, prefix, base32.StdEncoding, nil, nil)
+	s3s, err := NewS3Store(bucket, prefix, base32.StdEncoding, nil, nil, nil)
 	assert.NoError(t, err)
 	testStore(t, s3s)
 }
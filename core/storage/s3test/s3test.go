@@ -0,0 +1,444 @@
+// An in-process fake of the subset of the S3 API used by Hoard for hermetic
+// tests of the S3 backend:
+//
+//	server := s3test.NewServer()
+//	defer server.Close()
+//	store, err := server.NewS3Store("bucket", "prefix", base32.StdEncoding, nil)
+//
+// Supported are path-style PUT, GET (including byte ranges), HEAD, and DELETE
+// of objects, multipart uploads, and ListObjectsV2. Buckets are created on
+// first use and requests are not authenticated.
+package s3test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/monax/hoard/core/storage"
+)
+
+// The region reported to clients
+const Region = "us-east-1"
+
+// An object held by the server
+type Object struct {
+	Data []byte
+	// Headers of the request that created the object (the PUT or for multipart
+	// uploads the initiating POST)
+	Header       http.Header
+	ETag         string
+	LastModified time.Time
+}
+
+type upload struct {
+	bucket string
+	key    string
+	header http.Header
+	parts  map[int][]byte
+}
+
+type Server struct {
+	*httptest.Server
+	mtx          sync.Mutex
+	buckets      map[string]map[string]*Object
+	uploads      map[string]*upload
+	nextUploadID int
+}
+
+// Start a fake S3 server listening on a local port. Close must be called to
+// stop it.
+func NewServer() *Server {
+	server := &Server{
+		buckets: make(map[string]map[string]*Object),
+		uploads: make(map[string]*upload),
+	}
+	server.Server = httptest.NewServer(server)
+	return server
+}
+
+// An AWS config pointing at the server
+func (s *Server) AWSConfig() *aws.Config {
+	return aws.NewConfig().
+		WithEndpoint(s.URL).
+		WithS3ForcePathStyle(true).
+		WithRegion(Region).
+		WithCredentials(credentials.NewStaticCredentials("s3test", "s3test", ""))
+}
+
+// Create an S3 store backed by the server
+func (s *Server) NewS3Store(bucket, prefix string,
+	addressEncoding storage.AddressEncoding,
+	uploadOptions *storage.S3UploadOptions) (storage.Store, error) {
+	return storage.NewS3Store(bucket, prefix, addressEncoding, s.AWSConfig(),
+		uploadOptions, nil)
+}
+
+// Get the object stored at key in bucket or nil if there is none
+func (s *Server) Object(bucket, key string) *Object {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.buckets[bucket][key]
+}
+
+// Get the keys of all objects in bucket in lexical order
+func (s *Server) Keys(bucket string) []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.keys(bucket, "")
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	bucket, key := splitPath(r.URL.Path)
+	if bucket == "" {
+		writeError(w, http.StatusBadRequest, "InvalidRequest",
+			"Only path-style requests to buckets are supported")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		s.listObjectsV2(w, r, bucket)
+	case key == "":
+		writeError(w, http.StatusNotImplemented, "NotImplemented",
+			"Bucket operation not supported")
+	case r.Method == http.MethodPost && hasParam(query, "uploads"):
+		s.createMultipartUpload(w, r, bucket, key)
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		s.uploadPart(w, r, query.Get("uploadId"), query.Get("partNumber"))
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		s.completeMultipartUpload(w, r, bucket, key, query.Get("uploadId"))
+	case r.Method == http.MethodDelete && query.Get("uploadId") != "":
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.putObject(w, r, bucket, key)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		s.getObject(w, r, bucket, key)
+	case r.Method == http.MethodDelete:
+		delete(s.bucket(bucket), key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented",
+			fmt.Sprintf("%s of object not supported", r.Method))
+	}
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, bucket,
+	key string) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	object := s.store(bucket, key, data, r.Header)
+	w.Header().Set("ETag", object.ETag)
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, bucket,
+	key string) {
+	object, ok := s.bucket(bucket)[key]
+	if !ok {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeError(w, http.StatusNotFound, "NoSuchKey",
+			"The specified key does not exist.")
+		return
+	}
+	w.Header().Set("ETag", object.ETag)
+	w.Header().Set("Last-Modified", object.LastModified.Format(http.TimeFormat))
+	data := object.Data
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		start, end, ok := parseRange(rangeHeader, len(data))
+		if !ok {
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange",
+				"The requested range is not satisfiable")
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %v-%v/%v", start,
+			end-1, len(data)))
+		data = data[start:end]
+		status = http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Name                  string
+	Prefix                string
+	KeyCount              int
+	MaxKeys               int
+	IsTruncated           bool
+	ContinuationToken     string `xml:",omitempty"`
+	NextContinuationToken string `xml:",omitempty"`
+	Contents              []listEntry
+}
+
+type listEntry struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int
+	StorageClass string
+}
+
+func (s *Server) listObjectsV2(w http.ResponseWriter, r *http.Request,
+	bucket string) {
+	query := r.URL.Query()
+	if query.Get("list-type") != "2" {
+		writeError(w, http.StatusNotImplemented, "NotImplemented",
+			"Only ListObjectsV2 is supported")
+		return
+	}
+	maxKeys := 1000
+	if query.Get("max-keys") != "" {
+		var err error
+		maxKeys, err = strconv.Atoi(query.Get("max-keys"))
+		if err != nil || maxKeys < 1 {
+			writeError(w, http.StatusBadRequest, "InvalidArgument",
+				"Invalid max-keys")
+			return
+		}
+	}
+	result := listBucketResult{
+		Name:              bucket,
+		Prefix:            query.Get("prefix"),
+		MaxKeys:           maxKeys,
+		ContinuationToken: query.Get("continuation-token"),
+	}
+	// Continuation tokens are simply the last key returned
+	for _, key := range s.keys(bucket, result.Prefix) {
+		if key <= result.ContinuationToken || key <= query.Get("start-after") {
+			continue
+		}
+		if len(result.Contents) == maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = result.Contents[maxKeys-1].Key
+			break
+		}
+		object := s.buckets[bucket][key]
+		result.Contents = append(result.Contents, listEntry{
+			Key:          key,
+			LastModified: object.LastModified.Format(time.RFC3339),
+			ETag:         object.ETag,
+			Size:         len(object.Data),
+			StorageClass: "STANDARD",
+		})
+	}
+	result.KeyCount = len(result.Contents)
+	writeXML(w, result)
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Bucket   string
+	Key      string
+	UploadId string
+}
+
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request,
+	bucket, key string) {
+	s.nextUploadID++
+	uploadID := strconv.Itoa(s.nextUploadID)
+	s.uploads[uploadID] = &upload{
+		bucket: bucket,
+		key:    key,
+		header: r.Header,
+		parts:  make(map[int][]byte),
+	}
+	writeXML(w, initiateMultipartUploadResult{
+		Bucket:   bucket,
+		Key:      key,
+		UploadId: uploadID,
+	})
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, uploadID,
+	partNumberString string) {
+	upload, ok := s.uploads[uploadID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload",
+			"The specified upload does not exist.")
+		return
+	}
+	partNumber, err := strconv.Atoi(partNumberString)
+	if err != nil || partNumber < 1 {
+		writeError(w, http.StatusBadRequest, "InvalidArgument",
+			"Invalid partNumber")
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	upload.parts[partNumber] = data
+	w.Header().Set("ETag", etag(data))
+}
+
+type completeMultipartUpload struct {
+	Parts []struct {
+		PartNumber int
+		ETag       string
+	} `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Location string
+	Bucket   string
+	Key      string
+	ETag     string
+}
+
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
+	bucket, key, uploadID string) {
+	upload, ok := s.uploads[uploadID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload",
+			"The specified upload does not exist.")
+		return
+	}
+	complete := new(completeMultipartUpload)
+	err := xml.NewDecoder(r.Body).Decode(complete)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	var data []byte
+	for i, part := range complete.Parts {
+		partData, ok := upload.parts[part.PartNumber]
+		if !ok || (i > 0 && part.PartNumber <= complete.Parts[i-1].PartNumber) ||
+			part.ETag != etag(partData) {
+			writeError(w, http.StatusBadRequest, "InvalidPart",
+				fmt.Sprintf("Part %v is missing, out of order, or has the wrong "+
+					"ETag", part.PartNumber))
+			return
+		}
+		data = append(data, partData...)
+	}
+	delete(s.uploads, uploadID)
+	object := s.store(bucket, key, data, upload.header)
+	writeXML(w, completeMultipartUploadResult{
+		Location: fmt.Sprintf("%s/%s/%s", s.URL, bucket, key),
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     object.ETag,
+	})
+}
+
+func (s *Server) store(bucket, key string, data []byte,
+	header http.Header) *Object {
+	object := &Object{
+		Data:         data,
+		Header:       header,
+		ETag:         etag(data),
+		LastModified: time.Now().UTC(),
+	}
+	s.bucket(bucket)[key] = object
+	return object
+}
+
+func (s *Server) bucket(bucket string) map[string]*Object {
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		objects = make(map[string]*Object)
+		s.buckets[bucket] = objects
+	}
+	return objects
+}
+
+func (s *Server) keys(bucket, prefix string) []string {
+	var keys []string
+	for key := range s.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitPath(path string) (bucket, key string) {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// Parse a single 'bytes=start-end' range into a half-open interval
+func parseRange(rangeHeader string, size int) (int, int, bool) {
+	spec := strings.TrimPrefix(rangeHeader, "bytes=")
+	bounds := strings.SplitN(spec, "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, false
+	}
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+	end := size
+	if bounds[1] != "" {
+		last, err := strconv.Atoi(bounds[1])
+		if err != nil || last < start {
+			return 0, 0, false
+		}
+		if last+1 < end {
+			end = last + 1
+		}
+	}
+	return start, end, true
+}
+
+func hasParam(query map[string][]string, name string) bool {
+	_, ok := query[name]
+	return ok
+}
+
+func etag(data []byte) string {
+	digest := md5.Sum(data)
+	return `"` + hex.EncodeToString(digest[:]) + `"`
+}
+
+type errorResponse struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string
+	Message string
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(errorResponse{
+		Code:    code,
+		Message: message,
+	})
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,73 @@
+package s3test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListObjectsV2Pagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := s3.New(session.Must(session.NewSession(server.AWSConfig())))
+
+	for i := 0; i < 5; i++ {
+		_, err := client.PutObject(&s3.PutObjectInput{
+			Bucket: aws.String("bucket"),
+			Key:    aws.String(fmt.Sprintf("prefix/%v", i)),
+		})
+		assert.NoError(t, err)
+	}
+	_, err := client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("other/0"),
+	})
+	assert.NoError(t, err)
+
+	var keys []string
+	pages := 0
+	err = client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:  aws.String("bucket"),
+		Prefix:  aws.String("prefix/"),
+		MaxKeys: aws.Int64(2),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		pages++
+		for _, object := range output.Contents {
+			keys = append(keys, *object.Key)
+		}
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{"prefix/0", "prefix/1", "prefix/2", "prefix/3",
+		"prefix/4"}, keys)
+}
+
+func TestAbortMultipartUpload(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := s3.New(session.Must(session.NewSession(server.AWSConfig())))
+
+	output, err := client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("key"),
+	})
+	assert.NoError(t, err)
+	_, err = client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String("bucket"),
+		Key:      aws.String("key"),
+		UploadId: output.UploadId,
+	})
+	assert.NoError(t, err)
+	_, err = client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String("bucket"),
+		Key:      aws.String("key"),
+		UploadId: output.UploadId,
+	})
+	assert.Error(t, err)
+	assert.Nil(t, server.Object("bucket", "key"))
+}
@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"io"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -12,6 +15,35 @@ func ErrorAddressNotFound(address []byte) error {
 		base64.StdEncoding.EncodeToString(address))
 }
 
+func ErrorAddressCorrupted(address []byte) error {
+	return status.Errorf(codes.DataLoss, "Data stored at address %s is "+
+		"corrupted since its hash does not match its address",
+		base64.StdEncoding.EncodeToString(address))
+}
+
+func ErrorDeleteUnsupported(store Store) error {
+	return status.Errorf(codes.Unimplemented, "%s does not support deletion",
+		store.Name())
+}
+
+func ErrorListUnsupported(store Store) error {
+	return status.Errorf(codes.Unimplemented, "%s does not support listing "+
+		"addresses", store.Name())
+}
+
+func ErrorSnapshotUnsupported(store Store) error {
+	return status.Errorf(codes.Unimplemented, "%s does not support snapshots",
+		store.Name())
+}
+
+func ErrorPresignUnsupported(store Store) error {
+	return status.Errorf(codes.Unimplemented, "%s does not support presigned "+
+		"URLs", store.Name())
+}
+
+// The expiry used for presigned URLs when none is requested
+const DefaultPresignExpiry = 15 * time.Minute
+
 type Locator interface {
 	// Provides a canonical external location for some data, typically a URI
 	Location(address []byte) string
@@ -42,11 +74,94 @@ type Store interface {
 	Locator
 }
 
+// Optionally implemented by a Store that can remove data
+type Deleter interface {
+	// Delete data stored at address, deleting an address at which no data is
+	// stored is not an error
+	Delete(address []byte) error
+}
+
+// Delete data at address from store, or return an Unimplemented status error
+// if store does not support deletion
+func Delete(store Store, address []byte) error {
+	deleter, ok := store.(Deleter)
+	if !ok {
+		return ErrorDeleteUnsupported(store)
+	}
+	return deleter.Delete(address)
+}
+
+// Optionally implemented by a Store that can enumerate the addresses it holds
+type Lister interface {
+	// Call fn with each address stored in no particular order. If an entry in
+	// the backend cannot be decoded as an address fn is called with a non-nil
+	// error describing it instead. Listing stops with the error returned by fn
+	// if it is non-nil.
+	List(fn func(address []byte, err error) error) error
+}
+
+// List the addresses in store, or return an Unimplemented status error if
+// store does not support listing
+func List(store Store, fn func(address []byte, err error) error) error {
+	lister, ok := store.(Lister)
+	if !ok {
+		return ErrorListUnsupported(store)
+	}
+	return lister.List(fn)
+}
+
+// Optionally implemented by a Store that can persist its contents on demand
+type Snapshotter interface {
+	Snapshot() error
+}
+
+// Snapshot store, or return an Unimplemented status error if store does not
+// support snapshots
+func Snapshot(store Store) error {
+	snapshotter, ok := store.(Snapshotter)
+	if !ok {
+		return ErrorSnapshotUnsupported(store)
+	}
+	return snapshotter.Snapshot()
+}
+
+// Release any resources held by store if it is an io.Closer (such as plugins
+// or stores that write snapshots on close)
+func Close(store Store) error {
+	closer, ok := store.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return closer.Close()
+}
+
+// Optionally implemented by a Store whose backend can issue URLs from which
+// data can be fetched directly
+type Presigner interface {
+	// Get a URL from which data at address can be fetched for expiry
+	Presign(address []byte, expiry time.Duration) (url string, err error)
+}
+
+// Get a presigned URL for address from store, or an Unimplemented status
+// error if store does not support it
+func Presign(store Store, address []byte, expiry time.Duration) (string, error) {
+	presigner, ok := store.(Presigner)
+	if !ok {
+		return "", ErrorPresignUnsupported(store)
+	}
+	return presigner.Presign(address, expiry)
+}
+
 type ContentAddressedStore interface {
 	ReadStore
 	Locator
+	Presigner
 	// Put the data at its address
 	Put(data []byte) (address []byte, err error)
+	// Put the data at an address it matches under MatchesAddress, which may
+	// have been made with another hash algorithm or be a legacy SHA256 address
+	// (such as when importing an archive)
+	PutAt(address, data []byte) error
 	// Get the address of some data without putting it at that address
 	Address(data []byte) (address []byte)
 }
@@ -62,6 +177,12 @@ type contentAddressedStore struct {
 	store Store
 }
 
+// Derives the address of data as its SHA256 digest
+func SHA256Addresser(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	return digest[:]
+}
+
 func NewContentAddressedStore(addresser func([]byte) []byte,
 	store Store) ContentAddressedStore {
 	return &contentAddressedStore{
@@ -80,6 +201,13 @@ func (cas *contentAddressedStore) Put(data []byte) ([]byte, error) {
 	return address, err
 }
 
+func (cas *contentAddressedStore) PutAt(address, data []byte) error {
+	if !MatchesAddress(address, data) {
+		return ErrorAddressCorrupted(address)
+	}
+	return cas.store.Put(address, data)
+}
+
 func (cas *contentAddressedStore) Get(address []byte) ([]byte, error) {
 	return cas.store.Get(address)
 }
@@ -91,3 +219,8 @@ func (cas *contentAddressedStore) Stat(address []byte) (*StatInfo, error) {
 func (cas *contentAddressedStore) Location(address []byte) string {
 	return cas.store.Location(address)
 }
+
+func (cas *contentAddressedStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	return Presign(cas.store, address, expiry)
+}
@@ -0,0 +1,302 @@
+// Conformance tests for storage.Store implementations. Backend authors can
+// check their Store behaves as Hoard expects with:
+//
+//	func TestMyStore(t *testing.T) {
+//		storagetest.TestStore(t, NewMyStore())
+//	}
+//
+// The optional Deleter and Lister interfaces are checked unless the store
+// reports them as Unimplemented.
+package storagetest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/monax/hoard/core/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// The size of the blob used to check large blobs survive intact
+const LargeBlobSize = 8 << 20
+
+// Number of goroutines used for concurrency checks
+const Concurrency = 16
+
+// Run the conformance suite against store, which should be empty. Each check
+// uses its own addresses so store is not expected to be emptied between them.
+func TestStore(t *testing.T, store storage.Store) {
+	t.Run("Name", func(t *testing.T) { testName(t, store) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, store) })
+	t.Run("PutGet", func(t *testing.T) { testPutGet(t, store) })
+	t.Run("Stat", func(t *testing.T) { testStat(t, store) })
+	t.Run("Overwrite", func(t *testing.T) { testOverwrite(t, store) })
+	t.Run("EmptyData", func(t *testing.T) { testEmptyData(t, store) })
+	t.Run("SpecialAddresses", func(t *testing.T) { testSpecialAddresses(t, store) })
+	t.Run("LargeBlob", func(t *testing.T) { testLargeBlob(t, store) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, store) })
+	t.Run("Location", func(t *testing.T) { testLocation(t, store) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, store) })
+	t.Run("List", func(t *testing.T) { testList(t, store) })
+}
+
+func testName(t *testing.T, store storage.Store) {
+	if store.Name() == "" {
+		t.Errorf("Name should be non-empty")
+	}
+}
+
+func testNotFound(t *testing.T, store storage.Store) {
+	address := []byte("storagetest-missing")
+	data, err := store.Get(address)
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Get of missing address should return NotFound status "+
+			"but got: %v", err)
+	}
+	if data != nil {
+		t.Errorf("Get of missing address should return nil data but got %q",
+			data)
+	}
+	statInfo, err := store.Stat(address)
+	if err != nil {
+		t.Errorf("Stat of missing address should not be an error but got: %s",
+			err)
+	} else if statInfo.Exists {
+		t.Errorf("Stat of missing address should report it does not exist")
+	}
+}
+
+func testPutGet(t *testing.T, store storage.Store) {
+	putGet(t, store, []byte("storagetest-put-get"), []byte("storagetest-data"))
+}
+
+func testStat(t *testing.T, store storage.Store) {
+	for _, size := range []int{1, 2, 100, 4096, 65537} {
+		address := []byte(fmt.Sprintf("storagetest-stat-%v", size))
+		put(t, store, address, randomBytes(t, size))
+		assertStat(t, store, address, size)
+	}
+}
+
+func testOverwrite(t *testing.T, store storage.Store) {
+	address := []byte("storagetest-overwrite")
+	put(t, store, address, []byte("first data that is longer"))
+	put(t, store, address, []byte("second data"))
+	assertGet(t, store, address, []byte("second data"))
+	assertStat(t, store, address, len("second data"))
+}
+
+func testEmptyData(t *testing.T, store storage.Store) {
+	address := []byte("storagetest-empty")
+	put(t, store, address, []byte{})
+	retrieved, err := store.Get(address)
+	if err != nil {
+		t.Errorf("Could not Get empty data: %s", err)
+	} else if len(retrieved) != 0 {
+		t.Errorf("Get of empty data returned %v bytes", len(retrieved))
+	}
+	assertStat(t, store, address, 0)
+}
+
+func testSpecialAddresses(t *testing.T, store storage.Store) {
+	addresses := [][]byte{
+		// Has a '/' under standard base64 encoding
+		{0, 0, 63, 0, 0},
+		// Has a '+' under standard base64 encoding
+		{0, 0, 62, 0, 0},
+		// Needs padding under base64 and base32
+		{1},
+		// Raw bytes that are not valid UTF-8 or are path-like
+		{0xff, 0xfe, 0x00},
+		[]byte("../.."),
+		// A SHA256-length address
+		bytes.Repeat([]byte{0xab}, 32),
+	}
+	for i, address := range addresses {
+		putGet(t, store, address, []byte(fmt.Sprintf("special-data-%v", i)))
+	}
+}
+
+func testLargeBlob(t *testing.T, store storage.Store) {
+	address := []byte("storagetest-large")
+	data := randomBytes(t, LargeBlobSize)
+	put(t, store, address, data)
+	assertGet(t, store, address, data)
+	assertStat(t, store, address, LargeBlobSize)
+}
+
+func testConcurrency(t *testing.T, store storage.Store) {
+	sharedAddress := []byte("storagetest-concurrent-shared")
+	sharedData := []byte("storagetest-concurrent-shared-data")
+	wg := new(sync.WaitGroup)
+	errCh := make(chan error, Concurrency*3)
+	for i := 0; i < Concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			address := []byte(fmt.Sprintf("storagetest-concurrent-%v", i))
+			data := []byte(fmt.Sprintf("storagetest-concurrent-data-%v", i))
+			err := store.Put(address, data)
+			if err != nil {
+				errCh <- fmt.Errorf("Put %s: %s", address, err)
+				return
+			}
+			retrieved, err := store.Get(address)
+			if err != nil {
+				errCh <- fmt.Errorf("Get %s: %s", address, err)
+			} else if !bytes.Equal(data, retrieved) {
+				errCh <- fmt.Errorf("Get %s returned %q but expected %q",
+					address, retrieved, data)
+			}
+			// Content-addressed callers can race to Put identical data
+			err = store.Put(sharedAddress, sharedData)
+			if err != nil {
+				errCh <- fmt.Errorf("Put %s: %s", sharedAddress, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		t.Errorf("Concurrent operation failed: %s", err)
+	}
+	assertGet(t, store, sharedAddress, sharedData)
+}
+
+func testLocation(t *testing.T, store storage.Store) {
+	address := []byte("storagetest-location")
+	put(t, store, address, []byte("data"))
+	if store.Location(address) == "" {
+		t.Errorf("Location should be non-empty")
+	}
+	if store.Location(address) == store.Location([]byte("storagetest-other")) {
+		t.Errorf("Location should differ between addresses")
+	}
+}
+
+func testDelete(t *testing.T, store storage.Store) {
+	address := []byte("storagetest-delete")
+	put(t, store, address, []byte("data"))
+	err := storage.Delete(store, address)
+	if grpc.Code(err) == codes.Unimplemented {
+		t.Skipf("Store does not support Delete: %s", err)
+	}
+	if err != nil {
+		t.Fatalf("Could not Delete: %s", err)
+	}
+	_, err = store.Get(address)
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Get of deleted address should return NotFound status "+
+			"but got: %v", err)
+	}
+	statInfo, err := store.Stat(address)
+	if err != nil {
+		t.Errorf("Could not Stat deleted address: %s", err)
+	} else if statInfo.Exists {
+		t.Errorf("Stat of deleted address should report it does not exist")
+	}
+	err = storage.Delete(store, address)
+	if err != nil {
+		t.Errorf("Delete of missing address should not be an error but got: %s",
+			err)
+	}
+}
+
+func testList(t *testing.T, store storage.Store) {
+	addresses := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		address := []byte(fmt.Sprintf("storagetest-list-%v", i))
+		put(t, store, address, []byte("data"))
+		addresses[string(address)] = false
+	}
+	err := storage.List(store, func(address []byte, err error) error {
+		if err != nil {
+			t.Errorf("List reported an entry that is not an address: %s", err)
+			return nil
+		}
+		if seen, ok := addresses[string(address)]; ok {
+			if seen {
+				t.Errorf("List returned address %q more than once", address)
+			}
+			addresses[string(address)] = true
+		}
+		return nil
+	})
+	if grpc.Code(err) == codes.Unimplemented {
+		t.Skipf("Store does not support List: %s", err)
+	}
+	if err != nil {
+		t.Fatalf("Could not List: %s", err)
+	}
+	for address, seen := range addresses {
+		if !seen {
+			t.Errorf("List did not return address %q", address)
+		}
+	}
+
+	stopErr := fmt.Errorf("stop")
+	calls := 0
+	err = storage.List(store, func(address []byte, err error) error {
+		calls++
+		return stopErr
+	})
+	if err != stopErr || calls != 1 {
+		t.Errorf("List should stop with the error returned by its callback")
+	}
+}
+
+func putGet(t *testing.T, store storage.Store, address, data []byte) {
+	_, err := store.Get(address)
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Get of address %v before Put should return NotFound status "+
+			"but got: %v", address, err)
+	}
+	put(t, store, address, data)
+	assertGet(t, store, address, data)
+	assertStat(t, store, address, len(data))
+}
+
+func put(t *testing.T, store storage.Store, address, data []byte) {
+	err := store.Put(address, data)
+	if err != nil {
+		t.Fatalf("Could not Put data at address %v: %s", address, err)
+	}
+}
+
+func assertGet(t *testing.T, store storage.Store, address, data []byte) {
+	retrieved, err := store.Get(address)
+	if err != nil {
+		t.Errorf("Could not Get data at address %v: %s", address, err)
+	} else if !bytes.Equal(data, retrieved) {
+		t.Errorf("Get at address %v returned %v bytes that differ from the %v "+
+			"bytes Put", address, len(retrieved), len(data))
+	}
+}
+
+func assertStat(t *testing.T, store storage.Store, address []byte, size int) {
+	statInfo, err := store.Stat(address)
+	if err != nil {
+		t.Errorf("Could not Stat address %v: %s", address, err)
+		return
+	}
+	if !statInfo.Exists {
+		t.Errorf("Stat should report that address %v exists", address)
+	}
+	if statInfo.Size != uint64(size) {
+		t.Errorf("Stat of address %v reported size %v but expected %v",
+			address, statInfo.Size, size)
+	}
+}
+
+func randomBytes(t *testing.T, size int) []byte {
+	bs := make([]byte, size)
+	_, err := rand.Read(bs)
+	if err != nil {
+		t.Fatalf("Could not generate random data: %s", err)
+	}
+	return bs
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/monax/hoard/core/sync"
 )
@@ -18,11 +19,11 @@ type syncStore struct {
 }
 
 // Wrap a Store to synchronise it with respect to address access. For each
-// address exactly one writer can enter the Put method of the underlying store
-// or multiple readers can enter the Get and Stat methods, but no simultaneous
-// readers (Getters, Statters) and writers (Putters) are allowed. Concurrent
-// reads and writes to different addresses are permitted so the underlying store
-// must be goroutine-safe across addresses.
+// address exactly one writer can enter the Put or Delete methods of the
+// underlying store or multiple readers can enter the Get and Stat methods, but
+// no simultaneous readers (Getters, Statters) and writers (Putters, Deleters)
+// are allowed. Concurrent reads and writes to different addresses are permitted
+// so the underlying store must be goroutine-safe across addresses.
 func NewSyncStore(store Store) *syncStore {
 	return &syncStore{
 		store: store,
@@ -51,10 +52,25 @@ func (ss *syncStore) Put(address, data []byte) error {
 	return ss.store.Put(address, data)
 }
 
+func (ss *syncStore) Delete(address []byte) error {
+	ss.mtx.Lock(address)
+	defer ss.mtx.Unlock(address)
+	return Delete(ss.store, address)
+}
+
+func (ss *syncStore) List(fn func(address []byte, err error) error) error {
+	return List(ss.store, fn)
+}
+
 func (ss *syncStore) Location(address []byte) string {
 	return ss.store.Location(address)
 }
 
+func (ss *syncStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	return Presign(ss.store, address, expiry)
+}
+
 func (ss *syncStore) Name() string {
 	return fmt.Sprintf("syncStore[mutexCount=%v]<%s>", ss.mtx.Size(),
 		ss.store.Name())
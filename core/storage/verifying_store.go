@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/monax/hoard/core/logging"
+)
+
+type verifyingStore struct {
+	store           Store
+	matchesAddress  func(address, data []byte) bool
+	repairStore     Store
+	quarantineStore Store
+	logger          log.Logger
+}
+
+// Decorates a Store so that data read from it is checked against the address
+// it was read from using matchesAddress (such as MatchesAddress) before it is
+// returned. Mismatches are logged and returned as an ErrorAddressCorrupted so
+// that callers can tell corruption apart from a wrong secret key.
+//
+// If quarantineStore is non-nil corrupted data is moved into it (and deleted
+// from store if store supports deletion) so that it can be inspected later. If
+// repairStore is non-nil it is used as a source of known-good data that
+// replaces the corrupted data in store and is returned in its place.
+func NewVerifyingStore(store Store,
+	matchesAddress func(address, data []byte) bool,
+	repairStore, quarantineStore Store, logger log.Logger) *verifyingStore {
+
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	vs := &verifyingStore{
+		store:           store,
+		matchesAddress:  matchesAddress,
+		repairStore:     repairStore,
+		quarantineStore: quarantineStore,
+	}
+	vs.logger = log.With(logger, "module", "storage", "store", vs.Name())
+	return vs
+}
+
+var _ Store = (*verifyingStore)(nil)
+
+func (vs *verifyingStore) Get(address []byte) ([]byte, error) {
+	data, err := vs.store.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	if vs.verify(address, data) {
+		return data, nil
+	}
+	logging.InfoMsg(vs.logger, "Data read does not match its address",
+		"address", formatAddress(address))
+
+	if vs.quarantineStore != nil {
+		vs.quarantine(address, data)
+	}
+	if vs.repairStore != nil {
+		repairedData, err := vs.repair(address)
+		if err == nil {
+			return repairedData, nil
+		}
+		logging.InfoMsg(vs.logger, "Could not repair corrupted data",
+			"address", formatAddress(address),
+			"repair_store", vs.repairStore.Name(),
+			"error", err)
+	}
+	return nil, ErrorAddressCorrupted(address)
+}
+
+func (vs *verifyingStore) Stat(address []byte) (*StatInfo, error) {
+	return vs.store.Stat(address)
+}
+
+func (vs *verifyingStore) Put(address, data []byte) error {
+	return vs.store.Put(address, data)
+}
+
+func (vs *verifyingStore) Delete(address []byte) error {
+	return Delete(vs.store, address)
+}
+
+func (vs *verifyingStore) Presign(address []byte,
+	expiry time.Duration) (string, error) {
+	return Presign(vs.store, address, expiry)
+}
+
+func (vs *verifyingStore) List(fn func(address []byte, err error) error) error {
+	return List(vs.store, fn)
+}
+
+func (vs *verifyingStore) Location(address []byte) string {
+	return vs.store.Location(address)
+}
+
+func (vs *verifyingStore) Name() string {
+	return fmt.Sprintf("verifyingStore<%s>", vs.store.Name())
+}
+
+func (vs *verifyingStore) Snapshot() error {
+	return Snapshot(vs.store)
+}
+
+// Close any of the underlying stores that hold resources needing release
+func (vs *verifyingStore) Close() error {
+	var firstErr error
+	for _, store := range []Store{vs.store, vs.repairStore, vs.quarantineStore} {
+		if store == nil {
+			continue
+		}
+		err := Close(store)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (vs *verifyingStore) verify(address, data []byte) bool {
+	return vs.matchesAddress(address, data)
+}
+
+func (vs *verifyingStore) quarantine(address, data []byte) {
+	err := vs.quarantineStore.Put(address, data)
+	if err != nil {
+		logging.InfoMsg(vs.logger, "Could not quarantine corrupted data",
+			"address", formatAddress(address),
+			"quarantine_store", vs.quarantineStore.Name(),
+			"error", err)
+		return
+	}
+	err = Delete(vs.store, address)
+	if err != nil {
+		logging.InfoMsg(vs.logger, "Could not delete quarantined data",
+			"address", formatAddress(address),
+			"error", err)
+		return
+	}
+	logging.InfoMsg(vs.logger, "Moved corrupted data to quarantine",
+		"address", formatAddress(address),
+		"quarantine_store", vs.quarantineStore.Name())
+}
+
+func (vs *verifyingStore) repair(address []byte) ([]byte, error) {
+	data, err := vs.repairStore.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	if !vs.verify(address, data) {
+		return nil, ErrorAddressCorrupted(address)
+	}
+	err = vs.store.Put(address, data)
+	if err != nil {
+		return nil, err
+	}
+	logging.InfoMsg(vs.logger, "Repaired corrupted data",
+		"address", formatAddress(address),
+		"repair_store", vs.repairStore.Name())
+	return data, nil
+}
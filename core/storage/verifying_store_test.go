@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestVerifyingStore(t *testing.T) {
+	store := NewMemoryStore()
+	vs := NewVerifyingStore(store, MatchesAddress, nil, nil, nil)
+
+	address := SHA256Addresser(bs("good data"))
+	assert.NoError(t, vs.Put(address, bs("good data")))
+	data, err := vs.Get(address)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("good data"), data)
+
+	assert.NoError(t, store.Put(address, bs("bad data")))
+	_, err = vs.Get(address)
+	assert.Equal(t, codes.DataLoss, grpc.Code(err))
+}
+
+func TestVerifyingStoreQuarantine(t *testing.T) {
+	store := NewMemoryStore()
+	quarantineStore := NewMemoryStore()
+	vs := NewVerifyingStore(store, MatchesAddress, nil, quarantineStore, nil)
+
+	address := SHA256Addresser(bs("good data"))
+	assert.NoError(t, store.Put(address, bs("bad data")))
+	_, err := vs.Get(address)
+	assert.Equal(t, codes.DataLoss, grpc.Code(err))
+
+	quarantined, err := quarantineStore.Get(address)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("bad data"), quarantined)
+
+	statInfo, err := store.Stat(address)
+	assert.NoError(t, err)
+	assert.False(t, statInfo.Exists, "corrupted data should be moved")
+}
+
+func TestVerifyingStoreRepair(t *testing.T) {
+	store := NewMemoryStore()
+	repairStore := NewMemoryStore()
+	quarantineStore := NewMemoryStore()
+	vs := NewVerifyingStore(store, MatchesAddress, repairStore,
+		quarantineStore, nil)
+
+	address := SHA256Addresser(bs("good data"))
+	assert.NoError(t, store.Put(address, bs("bad data")))
+
+	// Repair store is also corrupted
+	assert.NoError(t, repairStore.Put(address, bs("also bad data")))
+	_, err := vs.Get(address)
+	assert.Equal(t, codes.DataLoss, grpc.Code(err))
+
+	assert.NoError(t, store.Put(address, bs("bad data")))
+	assert.NoError(t, repairStore.Put(address, bs("good data")))
+	data, err := vs.Get(address)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("good data"), data)
+
+	// Repaired in place
+	data, err = store.Get(address)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("good data"), data)
+
+	quarantined, err := quarantineStore.Get(address)
+	assert.NoError(t, err)
+	assert.Equal(t, bs("bad data"), quarantined)
+}
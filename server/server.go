@@ -15,17 +15,23 @@ import (
 )
 
 type server struct {
-	listenURL  string
-	store      storage.Store
-	grpcServer *grpc.Server
-	logger     log.Logger
+	listenURL          string
+	store              storage.Store
+	hashAlgorithm      *storage.HashAlgorithm
+	convergenceSecrets map[string][]byte
+	grpcServer         *grpc.Server
+	logger             log.Logger
 }
 
-func New(listenURL string, store storage.Store, logger log.Logger) *server {
+func New(listenURL string, store storage.Store,
+	hashAlgorithm *storage.HashAlgorithm, convergenceSecrets map[string][]byte,
+	logger log.Logger) *server {
 	return &server{
-		listenURL: listenURL,
-		store:     store,
-		logger:    logger,
+		listenURL:          listenURL,
+		store:              store,
+		hashAlgorithm:      hashAlgorithm,
+		convergenceSecrets: convergenceSecrets,
+		logger:             logger,
 	}
 }
 
@@ -48,7 +54,8 @@ func (serv *server) Serve() error {
 
 	logging.InfoMsg(serv.logger, "Initialising Hoard server",
 		"store_name", serv.store.Name())
-	hoardServer := core.NewHoardServer(core.NewHoard(serv.store, serv.logger))
+	hoardServer := core.NewHoardServer(core.NewHoard(serv.store, serv.hashAlgorithm,
+		serv.convergenceSecrets, serv.logger))
 
 	core.RegisterCleartextServer(serv.grpcServer, hoardServer)
 	core.RegisterEncryptionServer(serv.grpcServer, hoardServer)